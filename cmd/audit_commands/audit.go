@@ -0,0 +1,31 @@
+package audit_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var AuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect and configure the local audit log",
+	Long: `Inspect and configure the local audit log.
+
+Every decrypt, edit, create, add-key and remove-key is appended to ~/.sopsctl/audit.log
+as a JSON line with the time, command, context, file and the data keys that were touched.
+Secret values are never written to the audit log.
+
+Hash chaining links every new entry to the one before it so removed or modified
+entries can be detected with 'sopsctl audit show --verify'.`,
+	Example: `  # Enable hash chaining for new audit entries
+  sopsctl audit --set-hash-chain=true`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.AuditSettings, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.AuditSettings, AuditCmd)
+	AuditCmd.AddCommand(AuditShowCmd)
+}
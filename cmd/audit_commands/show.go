@@ -0,0 +1,29 @@
+package audit_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var AuditShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show entries of the local audit log",
+	Long:  `Show entries of the local audit log, optionally filtered by command, context, file, outcome and time.`,
+	Example: `  # Show every use of the production key in the last week
+  sopsctl audit show --cluster=production --since=168h
+
+  # Show failed decrypts as JSON lines
+  sopsctl audit show --command=secret-decrypt --outcome=failure --json
+
+  # Verify the hash chain before showing entries
+  sopsctl audit show --verify`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.AuditShow, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.AuditShow, AuditShowCmd)
+}
@@ -14,7 +14,15 @@ var KeyAddCmd = &cobra.Command{
 			
 You can also use the current context with the --from-current-context flag.
 
-Either --from-current-context or --context <ctx-name> must be specified`,
+Either --from-current-context or --context <ctx-name> must be specified
+
+Use --public-key or --from-pubkey-file to register an encrypt-only context. Only the
+public key is stored, so secrets can be created for the cluster but not decrypted or edited.`,
+	Example: `  # Register an encrypt-only context from the public key committed to the repository
+  sopsctl add-key --cluster=production --from-pubkey-file=clusters/production/.sops.pub.age
+
+  # Register an encrypt-only context from a public key
+  sopsctl add-key --cluster=production --public-key=age1...`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		pkg.ExecuteCobraCommand(domain.KeyAdd, cmd, args)
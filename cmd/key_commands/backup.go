@@ -0,0 +1,30 @@
+package key_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var KeyBackupCmd = &cobra.Command{
+	Use:   "backup [context...]",
+	Short: "Export stored SOPS keys as an age-encrypted backup",
+	Long: `Export all stored SOPS keys, or only those of the given contexts, together with where
+they came from as a single backup encrypted to one or more age recipients.
+
+Keys of contexts in cluster storage mode are read from the cluster, so the backup can be
+kept as an offline escrow in case a cluster and its key are lost.`,
+	Example: `  # Back up all keys for the platform team
+  sopsctl key backup --to age1... -o keys.backup
+
+  # Back up only the production key
+  sopsctl key backup production --to age1... -o production.backup`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.KeyBackup, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.KeyBackup, KeyBackupCmd)
+}
@@ -0,0 +1,33 @@
+package key_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var KeyGroupCmd = &cobra.Command{
+	Use:   "group [name]",
+	Short: "Define named groups of clusters to encrypt secrets for",
+	Long: `Define named groups of clusters. A group name can be passed to --cluster wherever a
+secret is encrypted, and the secret is then encrypted for every member of the group, so
+each of them can decrypt it.
+
+Without flags the defined groups are listed.`,
+	Example: `  # Define the production group
+  sopsctl key group production --set prod-eu,prod-us
+
+  # Create a secret every production cluster can decrypt
+  sopsctl create datadog --from-literal=api-key=... --cluster production
+
+  # Delete the group
+  sopsctl key group production --delete`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.KeyGroup, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.KeyGroup, KeyGroupCmd)
+}
@@ -0,0 +1,34 @@
+package key_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var KeyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Maintain and configure stored SOPS keys",
+	Long: `Maintain the SOPS keys stored in local storage and configure how keys are looked up.
+
+With the sops fallback enabled, decrypt and edit also consider the age keys sops itself
+reads from SOPS_AGE_KEY_FILE, SOPS_AGE_KEY and ~/.config/sops/age/keys.txt when no stored
+key matches a file's recipients. It is disabled by default.`,
+	Example: `  # Also decrypt files encrypted to your personal age key
+  sopsctl key --set-sops-fallback=true`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.KeySettings, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.KeySettings, KeyCmd)
+	KeyCmd.AddCommand(KeyRefreshCmd)
+	KeyCmd.AddCommand(KeyPruneCmd)
+	KeyCmd.AddCommand(KeyBackupCmd)
+	KeyCmd.AddCommand(KeyRestoreCmd)
+	KeyCmd.AddCommand(KeyShareCmd)
+	KeyCmd.AddCommand(KeyReceiveCmd)
+	KeyCmd.AddCommand(KeyGroupCmd)
+}
@@ -0,0 +1,31 @@
+package key_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var KeyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove stored SOPS keys for contexts that no longer exist in kubeconfig",
+	Long: `Remove stored SOPS keys for contexts that are no longer present in the merged kubeconfig,
+for example because the cluster was torn down.
+
+The orphaned contexts are listed and removed after confirmation. Encrypt-only contexts are
+kept unless --include-encrypt-only is given, since they usually belong to clusters you
+have no kubeconfig access to.`,
+	Example: `  # Show which keys would be removed
+  sopsctl key prune --dry-run
+
+  # Remove orphaned keys without asking
+  sopsctl key prune --yes`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.KeyPrune, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.KeyPrune, KeyPruneCmd)
+}
@@ -0,0 +1,29 @@
+package key_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var KeyReceiveCmd = &cobra.Command{
+	Use:   "receive <file|->",
+	Short: "Import a SOPS key shared with 'sopsctl key share'",
+	Long: `Import a SOPS key a teammate shared with 'sopsctl key share', decrypting it with your
+age identity. The key is stored locally and list-keys shows who shared it.
+
+Use --as when your kubeconfig names the cluster differently than the sender's.`,
+	Example: `  # Import a shared key
+  sopsctl key receive staging.key.age -i ~/.config/sops/age/keys.txt
+
+  # Import a shared key from stdin under another context name
+  sopsctl key receive - -i ~/.config/sops/age/keys.txt --as my-staging < staging.key.age`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.KeyReceive, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.KeyReceive, KeyReceiveCmd)
+}
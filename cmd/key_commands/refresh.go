@@ -0,0 +1,29 @@
+package key_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var KeyRefreshCmd = &cobra.Command{
+	Use:   "refresh [context]",
+	Short: "Re-fetch stored SOPS keys from the cluster they were added from",
+	Long: `Re-fetch locally stored SOPS keys from the cluster secret recorded when they were added,
+for example after the key was rotated, and report which keys changed.
+
+Without a context name or --all the current context is refreshed.`,
+	Example: `  # Refresh the key of the production context
+  sopsctl key refresh production
+
+  # Refresh all stored keys
+  sopsctl key refresh --all`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.KeyRefresh, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.KeyRefresh, KeyRefreshCmd)
+}
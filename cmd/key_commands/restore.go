@@ -0,0 +1,30 @@
+package key_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var KeyRestoreCmd = &cobra.Command{
+	Use:   "restore <backup-file> [context...]",
+	Short: "Import SOPS keys from a backup created with key backup",
+	Long: `Import SOPS keys from a backup created with 'sopsctl key backup', decrypting it with
+the age identity it was encrypted to.
+
+Contexts that already have a key stored are skipped unless --force is given. Restored
+keys are stored locally, since the cluster they came from may no longer exist.`,
+	Example: `  # Restore all keys from a backup
+  sopsctl key restore keys.backup -i ~/.config/sops/age/keys.txt
+
+  # Restore only the production key, replacing the stored one
+  sopsctl key restore keys.backup production -i team.agekey --force`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.KeyRestore, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.KeyRestore, KeyRestoreCmd)
+}
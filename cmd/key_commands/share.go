@@ -0,0 +1,27 @@
+package key_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var KeyShareCmd = &cobra.Command{
+	Use:   "share <context>",
+	Short: "Encrypt a stored SOPS key for a teammate's age public key",
+	Long: `Encrypt the SOPS key of a context to a teammate's age public key, so they can import it
+with 'sopsctl key receive' without needing access to the cluster secret.
+
+The key is never printed in plaintext, the output is an age-encrypted file that only the
+recipient can open.`,
+	Example: `  # Share the staging key with a teammate
+  sopsctl key share staging --to age1... -o staging.key.age`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.KeyShare, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.KeyShare, KeyShareCmd)
+}
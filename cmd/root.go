@@ -3,6 +3,7 @@ package cmd
 
 import (
 	"os"
+	"sopsctl/cmd/audit_commands"
 	"sopsctl/cmd/key_commands"
 	"sopsctl/cmd/secret_commands"
 
@@ -40,14 +41,19 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringP("cluster", "c", "", "Kubernetes cluster context to use")
+	rootCmd.PersistentFlags().StringP("cluster", "c", "", "Kubernetes cluster context to use, a comma separated list or cluster group encrypts for several clusters")
 
 	rootCmd.AddCommand(secret_commands.SecretDecryptCmd)
 	rootCmd.AddCommand(secret_commands.SecretEditCmd)
 	rootCmd.AddCommand(secret_commands.SecretCreateCmd)
+	rootCmd.AddCommand(secret_commands.SecretEncryptCmd)
+	rootCmd.AddCommand(secret_commands.SecretSetCmd)
 
 	rootCmd.AddCommand(key_commands.KeyAddCmd)
 	rootCmd.AddCommand(key_commands.KeyListCmd)
 	rootCmd.AddCommand(key_commands.RemoveCmd)
 	rootCmd.AddCommand(key_commands.KeyStorageModeCmd)
+	rootCmd.AddCommand(key_commands.KeyCmd)
+
+	rootCmd.AddCommand(audit_commands.AuditCmd)
 }
@@ -29,7 +29,22 @@ the directory will be packaged into the secret.`,
   sopsctl secret create my-secret --from-literal=key1=supersecret --from-literal=key2=topsecret
 
   # Create a new secret from env files
-  sopsctl secret create my-secret --from-env-file=path/to/foo.env --from-env-file=path/to/bar.env`,
+  sopsctl secret create my-secret --from-env-file=path/to/foo.env --from-env-file=path/to/bar.env
+
+  # Create a new secret without the password appearing in argv or the shell history
+  sopsctl secret create my-secret --from-literal=username=app --prompt=password
+
+  # Create a new secret with a generated password that is never printed
+  sopsctl secret create my-secret --from-literal=username=app --from-random=password=32:alnum
+
+  # Create a new secret holding a generated SSH deploy key
+  sopsctl secret create deploy-key --generate-ssh-keypair=identity=ed25519
+
+  # Create a new secret whose values read as plain text once decrypted
+  sopsctl secret create my-secret --from-literal=password=topsecret --string-data
+
+  # Write the secret to a file and add it to the kustomization.yaml next to it
+  sopsctl secret create my-secret --from-literal=key1=supersecret -o apps/my-secret.enc.yaml --add-to-kustomization`,
 	Run: func(cmd *cobra.Command, args []string) {
 		pkg.ExecuteCobraCommand(domain.SecretCreate, cmd, args)
 	},
@@ -37,4 +52,9 @@ the directory will be packaged into the secret.`,
 
 func init() {
 	pkg.InitCobraCommand(domain.SecretCreate, SecretCreateCmd)
+	SecretCreateCmd.AddCommand(SecretCreateTLSCmd)
+	SecretCreateCmd.AddCommand(SecretCreateDockerRegistryCmd)
+	SecretCreateCmd.AddCommand(SecretCreateFluxAuthCmd)
+	SecretCreateCmd.AddCommand(SecretCreateBasicAuthCmd)
+	SecretCreateCmd.AddCommand(SecretCreateHelmValuesCmd)
 }
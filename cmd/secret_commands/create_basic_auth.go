@@ -0,0 +1,32 @@
+package secret_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var SecretCreateBasicAuthCmd = &cobra.Command{
+	Use:   "basic-auth NAME --user=name [--user=name...] [--generate-passwords[=length[:charset]]] [--passwords-output=path]",
+	Short: "Create an encrypted htpasswd secret for basic authentication",
+	Long: `Create an encrypted secret with an htpasswd file under the auth key, as referenced by
+the nginx.ingress.kubernetes.io/auth-secret annotation of ingress-nginx.
+
+The password of each user is prompted for, or generated with --generate-passwords, and
+stored as a bcrypt hash. With --passwords-output the plain text passwords are also
+written, keyed by user, to a separate encrypted secret named NAME-passwords. Generated
+passwords require --passwords-output, since they are never printed.`,
+	Example: `  # Create an htpasswd secret, prompting for the passwords
+  sopsctl create basic-auth dashboard-auth --user=alice --user=bob
+
+  # Generate the passwords and keep them in a separate encrypted secret
+  sopsctl create basic-auth dashboard-auth --user=alice --generate-passwords -o auth.yaml --passwords-output=passwords.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.SecretCreateBasicAuth, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.SecretCreateBasicAuth, SecretCreateBasicAuthCmd)
+}
@@ -0,0 +1,30 @@
+package secret_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var SecretCreateDockerRegistryCmd = &cobra.Command{
+	Use:   "docker-registry NAME --docker-username=user --docker-password=password --docker-email=email [--docker-server=string] [--from-file=[key=]source]",
+	Short: "Create an encrypted secret for use with a Docker registry",
+	Long: `Create an encrypted secret for use with a Docker registry.
+
+Dockercfg secrets are used to authenticate against Docker registries. The secret holds
+a .dockerconfigjson built from the given credentials, or the content of an existing
+~/.docker/config.json given with --from-file.`,
+	Example: `  # Create a new docker-registry secret named my-secret
+  sopsctl create docker-registry my-secret --docker-server=ghcr.io --docker-username=tiger --docker-password=pass1234
+
+  # Create a new docker-registry secret named my-secret from ~/.docker/config.json
+  sopsctl create docker-registry my-secret --from-file=path/to/.docker/config.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.SecretCreateDockerRegistry, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.SecretCreateDockerRegistry, SecretCreateDockerRegistryCmd)
+}
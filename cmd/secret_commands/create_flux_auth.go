@@ -0,0 +1,42 @@
+package secret_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var SecretCreateFluxAuthCmd = &cobra.Command{
+	Use:   "flux-auth NAME (--git-ssh --known-hosts-from=host | --basic-auth=user[:password] | --bearer-token=token) [--ca-file=path]",
+	Short: "Create an encrypted authentication secret for a Flux source",
+	Long: `Create an encrypted authentication secret for a Flux GitRepository, HelmRepository or
+OCIRepository, with exactly the keys Flux expects:
+
+  --git-ssh        identity, identity.pub and known_hosts
+  --basic-auth     username and password
+  --bearer-token   bearerToken
+  --ca-file        ca.crt, alone or together with one of the above
+
+The private key, known_hosts and CA certificate are validated before encryption. Without
+--private-key-file a new ed25519 deploy key is generated and its public key is printed
+to stderr. Without a password, --basic-auth prompts for it.`,
+	Example: `  # Create an SSH secret for a GitRepository with a new deploy key
+  sopsctl create flux-auth flux-system --git-ssh --known-hosts-from=github.com
+
+  # Create an SSH secret from an existing key
+  sopsctl create flux-auth flux-system --git-ssh --private-key-file=./identity --known-hosts-file=./known_hosts
+
+  # Create a basic auth secret for a HelmRepository, prompting for the password
+  sopsctl create flux-auth charts --basic-auth=robot
+
+  # Create a bearer token secret with a private CA
+  sopsctl create flux-auth registry --bearer-token=$TOKEN --ca-file=ca.crt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.SecretCreateFluxAuth, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.SecretCreateFluxAuth, SecretCreateFluxAuthCmd)
+}
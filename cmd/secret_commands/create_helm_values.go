@@ -0,0 +1,31 @@
+package secret_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var SecretCreateHelmValuesCmd = &cobra.Command{
+	Use:   "helm-values NAME --from-values=path [--values-key=key]",
+	Short: "Create an encrypted secret with Helm values for a Flux HelmRelease",
+	Long: `Create an encrypted secret with the content of a Helm values file, for the valuesFrom
+list of a Flux HelmRelease.
+
+The values file must be a single YAML document with a map at the top level. It is stored
+as is, comments included, under --values-key (default: values.yaml). The matching
+valuesFrom entry is printed to stderr.`,
+	Example: `  # Create a secret with the sensitive values of a chart
+  sopsctl create helm-values podinfo-values --from-values=values.secret.yaml -n podinfo
+
+  # Store the values under a custom key and write the secret next to the HelmRelease
+  sopsctl create helm-values podinfo-values --from-values=values.secret.yaml --values-key=secret-values.yaml -o apps/podinfo/values-secret.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.SecretCreateHelmValues, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.SecretCreateHelmValues, SecretCreateHelmValuesCmd)
+}
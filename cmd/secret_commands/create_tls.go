@@ -0,0 +1,26 @@
+package secret_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var SecretCreateTLSCmd = &cobra.Command{
+	Use:   "tls NAME --cert=path/to/cert/file --key=path/to/key/file",
+	Short: "Create an encrypted TLS secret",
+	Long: `Create an encrypted TLS secret from the given public/private key pair.
+
+The public/private key pair must exist beforehand. The public key certificate must be
+PEM encoded and match the given private key.`,
+	Example: `  # Create a new TLS secret named tls-secret with the given key pair
+  sopsctl create tls tls-secret --cert=path/to/tls.crt --key=path/to/tls.key`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.SecretCreateTLS, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.SecretCreateTLS, SecretCreateTLSCmd)
+}
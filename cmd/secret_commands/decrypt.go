@@ -15,9 +15,11 @@ var SecretDecryptCmd = &cobra.Command{
 This command retrieves the private AGE key for the specified cluster from the key manager
 and uses it to decrypt the provided file. The decrypted content is output to stdout.
 
+Without --cluster the key is selected by matching the age recipients in the file's sops
+metadata against the stored keys, preferring the current context.
+
 The file should be encrypted with SOPS using AGE encryption. The command supports
-YAML formatted files and requires a valid cluster context to retrieve the correct
-decryption key.
+YAML formatted files.
 
 Example:
   sopsctl secret decrypt secret.yaml --cluster=production`,
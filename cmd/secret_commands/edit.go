@@ -18,6 +18,9 @@ This command provides a secure workflow for editing encrypted secrets:
 3. After you save and close the editor, re-encrypts the content with the cluster's public key
 4. Atomically writes the encrypted content back to the original file
 
+Without --cluster the key is selected by matching the age recipients in the file's sops
+metadata against the stored keys, preferring the current context.
+
 The original encrypted file is never exposed in plain text on disk except in a
 temporary file during editing. The command ensures data integrity through atomic
 file operations.
@@ -0,0 +1,37 @@
+package secret_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var SecretEncryptCmd = &cobra.Command{
+	Use:   "encrypt <file|dir>",
+	Short: "Encrypt existing plaintext Secret manifests with SOPS",
+	Long: `Encrypt existing plaintext Secret manifests for the cluster given by --cluster, which
+can also be a comma separated list or a cluster group.
+
+A single file is printed encrypted to stdout, or replaced with --in-place. A directory
+is always encrypted in place, which migrates a whole directory of plaintext secrets at
+once. Files that are already encrypted are skipped, as are manifests of other kinds
+than Secret unless --allow-any-kind is given.
+
+Only the values matching --encrypted-regex are encrypted, by default data and stringData.`,
+	Example: `  # Print the encrypted version of a secret
+  sopsctl encrypt secret.yaml --cluster=production
+
+  # Encrypt all plaintext secrets below a directory in place
+  sopsctl encrypt clusters/production -r --in-place --cluster=production
+
+  # Encrypt the values of a ConfigMap
+  sopsctl encrypt configmap.yaml --allow-any-kind --encrypted-regex='^data$'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.SecretEncrypt, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.SecretEncrypt, SecretEncryptCmd)
+}
@@ -0,0 +1,33 @@
+package secret_commands
+
+import (
+	"sopsctl/pkg"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+)
+
+var SecretSetCmd = &cobra.Command{
+	Use:   "set <file> --from-random=key=length[:charset]",
+	Short: "Set values in an encrypted secret without opening an editor",
+	Long: `Set values in an existing SOPS-encrypted secret file without opening an editor.
+
+The file is decrypted in memory, the values are replaced and the file is re-encrypted
+for the same age recipients. A key kept under stringData stays there, other keys are
+written base64 encoded under data. Generated values are never printed.
+
+Without --cluster the key is selected by matching the age recipients in the file's sops
+metadata against the stored keys, preferring the current context.`,
+	Example: `  # Rotate the database password in an existing secret
+  sopsctl set db.enc.yaml --from-random=db-password=32:alnum
+
+  # Rotate several values at once
+  sopsctl set api.enc.yaml --from-random=token=64:hex --from-random=signing-key=32:base64`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pkg.ExecuteCobraCommand(domain.SecretSet, cmd, args)
+	},
+}
+
+func init() {
+	pkg.InitCobraCommand(domain.SecretSet, SecretSetCmd)
+}
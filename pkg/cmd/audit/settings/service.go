@@ -0,0 +1,65 @@
+package settings
+
+import (
+	"fmt"
+	"sopsctl/pkg/cmd/help"
+	"sopsctl/pkg/domain"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+const setHashChainFlagName = "set-hash-chain"
+
+type auditSettingsCmdOptions struct {
+	HashChain bool
+}
+
+type AuditSettingsCmd struct {
+	options  *auditSettingsCmdOptions
+	storage  domain.KeyStorage
+	auditLog domain.AuditLog
+}
+
+func NewAuditSettingsCmd(storage domain.KeyStorage, auditLog domain.AuditLog) *AuditSettingsCmd {
+	return &AuditSettingsCmd{storage: storage, auditLog: auditLog}
+}
+
+func (a AuditSettingsCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	hashChainStr, err := cmd.Flags().GetString(setHashChainFlagName)
+	if err != nil {
+		return nil, err
+	}
+	if hashChainStr == "" {
+		return help.NewHelpExecutor(cmd), nil
+	}
+	hashChain, err := strconv.ParseBool(hashChainStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for --%s: %s", setHashChainFlagName, hashChainStr)
+	}
+	a.options = &auditSettingsCmdOptions{HashChain: hashChain}
+	return a, nil
+}
+
+func (a AuditSettingsCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().String(setHashChainFlagName, "", "Chain new audit entries to the previous entry by hash so tampering can be detected (true, false)")
+}
+
+func (a AuditSettingsCmd) Execute() (string, error) {
+	if !a.options.HashChain {
+		// Recorded while chaining is still on, so the chain shows it was turned off on purpose
+		a.auditLog.Record(domain.NewHashChainAuditEntry(false, nil))
+	}
+	err := a.storage.SetAuditHashChain(a.options.HashChain)
+	if a.options.HashChain {
+		// Recorded once chaining is on, so it is the first chained entry
+		a.auditLog.Record(domain.NewHashChainAuditEntry(true, err))
+	}
+	if err != nil {
+		return "", err
+	}
+	if a.options.HashChain {
+		return "audit log hash chaining enabled", nil
+	}
+	return "audit log hash chaining disabled", nil
+}
@@ -0,0 +1,13 @@
+package show
+
+import "sopsctl/pkg/domain"
+
+type AuditShowCmdOptions struct {
+	Filter domain.AuditFilter
+	Verify bool
+	Json   bool
+}
+
+func NewAuditShowCmdOptions(filter domain.AuditFilter, verify bool, json bool) *AuditShowCmdOptions {
+	return &AuditShowCmdOptions{Filter: filter, Verify: verify, Json: json}
+}
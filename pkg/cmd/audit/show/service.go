@@ -0,0 +1,149 @@
+package show
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sopsctl/pkg/domain"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	commandFlagName = "command"
+	fileFlagName    = "file"
+	outcomeFlagName = "outcome"
+	sinceFlagName   = "since"
+	verifyFlagName  = "verify"
+	jsonFlagName    = "json"
+	clusterFlagName = "cluster"
+)
+
+type AuditShowCmd struct {
+	options  *AuditShowCmdOptions
+	auditLog domain.AuditLog
+}
+
+func NewAuditShowCmd(auditLog domain.AuditLog) *AuditShowCmd {
+	return &AuditShowCmd{auditLog: auditLog}
+}
+
+func (a AuditShowCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().String(commandFlagName, "", "Only show entries for this command (e.g. secret-decrypt, secret-edit, secret-create, key-add, key-remove)")
+	cmd.Flags().String(fileFlagName, "", "Only show entries for this file")
+	cmd.Flags().String(outcomeFlagName, "", "Only show entries with this outcome (success, failure)")
+	cmd.Flags().String(sinceFlagName, "", "Only show entries newer than a duration (e.g. 24h) or a date (e.g. 2025-01-31)")
+	cmd.Flags().Bool(verifyFlagName, false, "Verify the hash chain of the audit log")
+	cmd.Flags().Bool(jsonFlagName, false, "Print entries as JSON lines")
+}
+
+func (a AuditShowCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	if len(args) > 0 {
+		return nil, fmt.Errorf("unexpected positional arguments: %v", args)
+	}
+	filter := domain.AuditFilter{}
+	filter.Command, _ = cmd.Flags().GetString(commandFlagName)
+	filter.Outcome, _ = cmd.Flags().GetString(outcomeFlagName)
+	if filter.Outcome != "" && filter.Outcome != domain.AuditOutcomeSuccess && filter.Outcome != domain.AuditOutcomeFailure {
+		return nil, fmt.Errorf("invalid outcome: %s", filter.Outcome)
+	}
+	// only filter on context when --cluster is given explicitly, not the current context
+	if cmd.Flags().Changed(clusterFlagName) {
+		filter.Context = cmd.Flags().Lookup(clusterFlagName).Value.String()
+	}
+	filePath, _ := cmd.Flags().GetString(fileFlagName)
+	if filePath != "" {
+		absoluteFilePath, err := filepath.Abs(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for file %s: %v", filePath, err)
+		}
+		filter.File = absoluteFilePath
+	}
+	since, _ := cmd.Flags().GetString(sinceFlagName)
+	if since != "" {
+		sinceTime, err := parseSince(since, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		filter.Since = sinceTime
+	}
+	verify, _ := cmd.Flags().GetBool(verifyFlagName)
+	asJson, _ := cmd.Flags().GetBool(jsonFlagName)
+
+	a.options = NewAuditShowCmdOptions(filter, verify, asJson)
+	return a, nil
+}
+
+func (a AuditShowCmd) Execute() (string, error) {
+	if a.options.Verify {
+		if err := a.auditLog.Verify(); err != nil {
+			return "", fmt.Errorf("audit log verification failed: %w", err)
+		}
+	}
+	entries, err := a.auditLog.Entries(a.options.Filter)
+	if err != nil {
+		return "", fmt.Errorf("read audit log: %w", err)
+	}
+
+	var output strings.Builder
+	if a.options.Verify {
+		output.WriteString(color.GreenString("Audit log hash chain verified.") + "\n")
+	}
+	if len(entries) == 0 && !a.options.Json {
+		output.WriteString(color.YellowString("No audit entries found."))
+		return output.String(), nil
+	}
+	for _, entry := range entries {
+		if a.options.Json {
+			line, err := json.Marshal(entry)
+			if err != nil {
+				return "", err
+			}
+			output.Write(line)
+			output.WriteString("\n")
+			continue
+		}
+		output.WriteString(formatEntry(entry) + "\n")
+	}
+	return strings.TrimSuffix(output.String(), "\n"), nil
+}
+
+func formatEntry(entry domain.AuditEntry) string {
+	outcome := color.GreenString(entry.Outcome)
+	if entry.Outcome != domain.AuditOutcomeSuccess {
+		outcome = color.RedString(entry.Outcome)
+	}
+	line := entry.Timestamp.Local().Format(time.RFC3339) + " " + color.CyanString(entry.Command) + " " + outcome
+	if entry.Context != "" {
+		line += " context=" + entry.Context
+	}
+	if entry.File != "" {
+		line += " file=" + entry.File
+	}
+	if len(entry.DataKeys) > 0 {
+		line += " keys=" + strings.Join(entry.DataKeys, ",")
+	}
+	if entry.User != "" {
+		line += " user=" + entry.User
+	}
+	if entry.Error != "" {
+		line += " error=" + color.RedString("%q", entry.Error)
+	}
+	return line
+}
+
+// parseSince accepts either a duration relative to now or an absolute date or timestamp.
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(since); err == nil {
+		return now.Add(-duration), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, since, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --%s value %q, use a duration like 24h or a date like 2025-01-31", sinceFlagName, since)
+}
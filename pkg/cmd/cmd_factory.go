@@ -9,34 +9,85 @@ import (
 
 type CommandFactoryParams struct {
 	dig.In
-	KeyAddCmdBuilder         domain.CommandBuilder `name:"key-add"`
-	KeyListCmdBuilder        domain.CommandBuilder `name:"key-list"`
-	KeyRemoveCmdBuilder      domain.CommandBuilder `name:"key-remove"`
-	SecretEditCmdBuilder     domain.CommandBuilder `name:"secret-edit"`
-	SecretDecryptCmdBuilder  domain.CommandBuilder `name:"secret-decrypt"`
-	KeyStorageModeCmdBuilder domain.CommandBuilder `name:"key-storage-mode"`
-	SecretCreateCmdBuilder   domain.CommandBuilder `name:"secret-create"`
+	KeyAddCmdBuilder                     domain.CommandBuilder `name:"key-add"`
+	KeyListCmdBuilder                    domain.CommandBuilder `name:"key-list"`
+	KeyRemoveCmdBuilder                  domain.CommandBuilder `name:"key-remove"`
+	SecretEditCmdBuilder                 domain.CommandBuilder `name:"secret-edit"`
+	SecretDecryptCmdBuilder              domain.CommandBuilder `name:"secret-decrypt"`
+	KeyStorageModeCmdBuilder             domain.CommandBuilder `name:"key-storage-mode"`
+	SecretCreateCmdBuilder               domain.CommandBuilder `name:"secret-create"`
+	AuditSettingsCmdBuilder              domain.CommandBuilder `name:"audit-settings"`
+	AuditShowCmdBuilder                  domain.CommandBuilder `name:"audit-show"`
+	KeyRefreshCmdBuilder                 domain.CommandBuilder `name:"key-refresh"`
+	KeyPruneCmdBuilder                   domain.CommandBuilder `name:"key-prune"`
+	KeyBackupCmdBuilder                  domain.CommandBuilder `name:"key-backup"`
+	KeyRestoreCmdBuilder                 domain.CommandBuilder `name:"key-restore"`
+	KeyShareCmdBuilder                   domain.CommandBuilder `name:"key-share"`
+	KeyReceiveCmdBuilder                 domain.CommandBuilder `name:"key-receive"`
+	KeySettingsCmdBuilder                domain.CommandBuilder `name:"key-settings"`
+	KeyGroupCmdBuilder                   domain.CommandBuilder `name:"key-group"`
+	SecretEncryptCmdBuilder              domain.CommandBuilder `name:"secret-encrypt"`
+	SecretCreateTLSCmdBuilder            domain.CommandBuilder `name:"secret-create-tls"`
+	SecretCreateDockerRegistryCmdBuilder domain.CommandBuilder `name:"secret-create-docker-registry"`
+	SecretSetCmdBuilder                  domain.CommandBuilder `name:"secret-set"`
+	SecretCreateFluxAuthCmdBuilder       domain.CommandBuilder `name:"secret-create-flux-auth"`
+	SecretCreateBasicAuthCmdBuilder      domain.CommandBuilder `name:"secret-create-basic-auth"`
+	SecretCreateHelmValuesCmdBuilder     domain.CommandBuilder `name:"secret-create-helm-values"`
 }
 
 type CommandFactory struct {
-	keyAddCmdBuilder         domain.CommandBuilder
-	keyListCmdBuilder        domain.CommandBuilder
-	keyRemoveCmdBuilder      domain.CommandBuilder
-	keyStorageModeCmdBuilder domain.CommandBuilder
-	secretEditCmdBuilder     domain.CommandBuilder
-	secretDecryptCmdBuilder  domain.CommandBuilder
-	secretCreateCmdBuilder   domain.CommandBuilder
+	keyAddCmdBuilder                     domain.CommandBuilder
+	keyListCmdBuilder                    domain.CommandBuilder
+	keyRemoveCmdBuilder                  domain.CommandBuilder
+	keyStorageModeCmdBuilder             domain.CommandBuilder
+	secretEditCmdBuilder                 domain.CommandBuilder
+	secretDecryptCmdBuilder              domain.CommandBuilder
+	secretCreateCmdBuilder               domain.CommandBuilder
+	auditSettingsCmdBuilder              domain.CommandBuilder
+	auditShowCmdBuilder                  domain.CommandBuilder
+	keyRefreshCmdBuilder                 domain.CommandBuilder
+	keyPruneCmdBuilder                   domain.CommandBuilder
+	keyBackupCmdBuilder                  domain.CommandBuilder
+	keyRestoreCmdBuilder                 domain.CommandBuilder
+	keyShareCmdBuilder                   domain.CommandBuilder
+	keyReceiveCmdBuilder                 domain.CommandBuilder
+	keySettingsCmdBuilder                domain.CommandBuilder
+	keyGroupCmdBuilder                   domain.CommandBuilder
+	secretEncryptCmdBuilder              domain.CommandBuilder
+	secretCreateTLSCmdBuilder            domain.CommandBuilder
+	secretCreateDockerRegistryCmdBuilder domain.CommandBuilder
+	secretSetCmdBuilder                  domain.CommandBuilder
+	secretCreateFluxAuthCmdBuilder       domain.CommandBuilder
+	secretCreateBasicAuthCmdBuilder      domain.CommandBuilder
+	secretCreateHelmValuesCmdBuilder     domain.CommandBuilder
 }
 
 func NewCommandFactory(params CommandFactoryParams) *CommandFactory {
 	return &CommandFactory{
-		keyAddCmdBuilder:         params.KeyAddCmdBuilder,
-		keyListCmdBuilder:        params.KeyListCmdBuilder,
-		keyRemoveCmdBuilder:      params.KeyRemoveCmdBuilder,
-		keyStorageModeCmdBuilder: params.KeyStorageModeCmdBuilder,
-		secretEditCmdBuilder:     params.SecretEditCmdBuilder,
-		secretDecryptCmdBuilder:  params.SecretDecryptCmdBuilder,
-		secretCreateCmdBuilder:   params.SecretCreateCmdBuilder,
+		keyAddCmdBuilder:                     params.KeyAddCmdBuilder,
+		keyListCmdBuilder:                    params.KeyListCmdBuilder,
+		keyRemoveCmdBuilder:                  params.KeyRemoveCmdBuilder,
+		keyStorageModeCmdBuilder:             params.KeyStorageModeCmdBuilder,
+		secretEditCmdBuilder:                 params.SecretEditCmdBuilder,
+		secretDecryptCmdBuilder:              params.SecretDecryptCmdBuilder,
+		secretCreateCmdBuilder:               params.SecretCreateCmdBuilder,
+		auditSettingsCmdBuilder:              params.AuditSettingsCmdBuilder,
+		auditShowCmdBuilder:                  params.AuditShowCmdBuilder,
+		keyRefreshCmdBuilder:                 params.KeyRefreshCmdBuilder,
+		keyPruneCmdBuilder:                   params.KeyPruneCmdBuilder,
+		keyBackupCmdBuilder:                  params.KeyBackupCmdBuilder,
+		keyRestoreCmdBuilder:                 params.KeyRestoreCmdBuilder,
+		keyShareCmdBuilder:                   params.KeyShareCmdBuilder,
+		keyReceiveCmdBuilder:                 params.KeyReceiveCmdBuilder,
+		keySettingsCmdBuilder:                params.KeySettingsCmdBuilder,
+		keyGroupCmdBuilder:                   params.KeyGroupCmdBuilder,
+		secretEncryptCmdBuilder:              params.SecretEncryptCmdBuilder,
+		secretCreateTLSCmdBuilder:            params.SecretCreateTLSCmdBuilder,
+		secretCreateDockerRegistryCmdBuilder: params.SecretCreateDockerRegistryCmdBuilder,
+		secretSetCmdBuilder:                  params.SecretSetCmdBuilder,
+		secretCreateFluxAuthCmdBuilder:       params.SecretCreateFluxAuthCmdBuilder,
+		secretCreateBasicAuthCmdBuilder:      params.SecretCreateBasicAuthCmdBuilder,
+		secretCreateHelmValuesCmdBuilder:     params.SecretCreateHelmValuesCmdBuilder,
 	}
 }
 
@@ -56,6 +107,40 @@ func (cf *CommandFactory) GetCommandBuilder(cmd domain.CommandId) domain.Command
 		return cf.secretCreateCmdBuilder
 	case domain.KeyStorageMode:
 		return cf.keyStorageModeCmdBuilder
+	case domain.AuditSettings:
+		return cf.auditSettingsCmdBuilder
+	case domain.AuditShow:
+		return cf.auditShowCmdBuilder
+	case domain.KeyRefresh:
+		return cf.keyRefreshCmdBuilder
+	case domain.KeyPrune:
+		return cf.keyPruneCmdBuilder
+	case domain.KeyBackup:
+		return cf.keyBackupCmdBuilder
+	case domain.KeyRestore:
+		return cf.keyRestoreCmdBuilder
+	case domain.KeyShare:
+		return cf.keyShareCmdBuilder
+	case domain.KeyReceive:
+		return cf.keyReceiveCmdBuilder
+	case domain.KeySettings:
+		return cf.keySettingsCmdBuilder
+	case domain.KeyGroup:
+		return cf.keyGroupCmdBuilder
+	case domain.SecretEncrypt:
+		return cf.secretEncryptCmdBuilder
+	case domain.SecretCreateTLS:
+		return cf.secretCreateTLSCmdBuilder
+	case domain.SecretCreateDockerRegistry:
+		return cf.secretCreateDockerRegistryCmdBuilder
+	case domain.SecretSet:
+		return cf.secretSetCmdBuilder
+	case domain.SecretCreateFluxAuth:
+		return cf.secretCreateFluxAuthCmdBuilder
+	case domain.SecretCreateBasicAuth:
+		return cf.secretCreateBasicAuthCmdBuilder
+	case domain.SecretCreateHelmValues:
+		return cf.secretCreateHelmValuesCmdBuilder
 
 	default:
 		panic(fmt.Errorf("unknown command: %s", cmd))
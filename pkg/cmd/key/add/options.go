@@ -5,8 +5,10 @@ type KeyAddCmdOptions struct {
 	Namespace  string
 	SecretName string
 	SecretKey  string
+	// PublicKey registers an encrypt-only context instead of fetching the key from the cluster.
+	PublicKey string
 }
 
-func NewKeyAddCmdOptions(cluster string, namespace string, secretName string, secretKey string) *KeyAddCmdOptions {
-	return &KeyAddCmdOptions{Cluster: cluster, Namespace: namespace, SecretName: secretName, SecretKey: secretKey}
+func NewKeyAddCmdOptions(cluster string, namespace string, secretName string, secretKey string, publicKey string) *KeyAddCmdOptions {
+	return &KeyAddCmdOptions{Cluster: cluster, Namespace: namespace, SecretName: secretName, SecretKey: secretKey, PublicKey: publicKey}
 }
@@ -2,15 +2,23 @@ package add
 
 import (
 	"fmt"
+	"os"
 	"sopsctl/pkg/domain"
 	"sopsctl/pkg/services/utils"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+const (
+	publicKeyFlagName      = "public-key"
+	fromPubKeyFileFlagName = "from-pubkey-file"
+)
+
 type KeyAddCmd struct {
 	options          KeyAddCmdOptions
 	secretKeyManager domain.SopsKeyManager
+	auditLog         domain.AuditLog
 }
 
 func (k KeyAddCmd) InitCmd(cmd *cobra.Command) {
@@ -18,10 +26,13 @@ func (k KeyAddCmd) InitCmd(cmd *cobra.Command) {
 	cmd.Flags().StringP("namespace", "n", "flux-system", "The namespace where the secret is located")
 	cmd.Flags().StringP("secret", "s", "sops-age", "The name of the secret containing the SOPS key")
 	cmd.Flags().StringP("key", "k", "age.agekey", "The key within the secret that holds the SOPS key")
+	cmd.Flags().String(publicKeyFlagName, "", "Register an encrypt-only context with this age public key (age1...) instead of reading the private key from the cluster")
+	cmd.Flags().String(fromPubKeyFileFlagName, "", "Register an encrypt-only context with the age public key in this file (e.g. .sops.pub.age)")
+	cmd.MarkFlagsMutuallyExclusive(publicKeyFlagName, fromPubKeyFileFlagName)
 }
 
-func NewKeyAddCmd(secretKeyManager domain.SopsKeyManager) *KeyAddCmd {
-	return &KeyAddCmd{secretKeyManager: secretKeyManager}
+func NewKeyAddCmd(secretKeyManager domain.SopsKeyManager, auditLog domain.AuditLog) *KeyAddCmd {
+	return &KeyAddCmd{secretKeyManager: secretKeyManager, auditLog: auditLog}
 }
 
 func (k KeyAddCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
@@ -39,14 +50,47 @@ func (k KeyAddCmd) UseOptions(cmd *cobra.Command, args []string) (domain.Command
 	secretName, _ := cmd.Flags().GetString("secret")
 	secretKey, _ := cmd.Flags().GetString("key")
 
-	k.options = *NewKeyAddCmdOptions(gFlags.Cluster, namespace, secretName, secretKey)
+	publicKey, _ := cmd.Flags().GetString(publicKeyFlagName)
+	pubKeyFile, _ := cmd.Flags().GetString(fromPubKeyFileFlagName)
+	if pubKeyFile != "" {
+		publicKey, err = readPublicKeyFile(pubKeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	k.options = *NewKeyAddCmdOptions(gFlags.Cluster, namespace, secretName, secretKey, publicKey)
 	return k, nil
 }
 
 func (k KeyAddCmd) Execute() (string, error) {
-	result, err := k.secretKeyManager.AddKeyFromCluster(k.options.Cluster, k.options.Namespace, k.options.SecretName, k.options.SecretKey)
+	var result string
+	var err error
+	if k.options.PublicKey != "" {
+		result, err = k.secretKeyManager.AddPublicKey(k.options.Cluster, k.options.PublicKey)
+	} else {
+		result, err = k.secretKeyManager.AddKeyFromCluster(k.options.Cluster, k.options.Namespace, k.options.SecretName, k.options.SecretKey)
+	}
+	k.auditLog.Record(domain.NewAuditEntry(domain.KeyAdd, k.options.Cluster, "", nil, err))
 	if err != nil {
 		return "", err
 	}
 	return result, nil
 }
+
+// readPublicKeyFile returns the first age public key in the file, skipping comments
+// and blank lines like the .sops.pub.age files committed in Flux repositories.
+func readPublicKeyFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read public key file %s: %w", path, err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return line, nil
+	}
+	return "", fmt.Errorf("no public key found in %s", path)
+}
@@ -0,0 +1,12 @@
+package backup
+
+type KeyBackupCmdOptions struct {
+	Recipients   []string
+	OutputFile   string
+	Force        bool
+	ClusterNames []string
+}
+
+func NewKeyBackupCmdOptions(recipients []string, outputFile string, force bool, clusterNames []string) *KeyBackupCmdOptions {
+	return &KeyBackupCmdOptions{Recipients: recipients, OutputFile: outputFile, Force: force, ClusterNames: clusterNames}
+}
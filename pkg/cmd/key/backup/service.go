@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/file"
+	"sopsctl/pkg/services/key"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	toFlagName     = "to"
+	outputFlagName = "output"
+	forceFlagName  = "force"
+)
+
+type KeyBackupCmd struct {
+	options  *KeyBackupCmdOptions
+	skm      domain.SopsKeyManager
+	auditLog domain.AuditLog
+}
+
+func NewKeyBackupCmd(skm domain.SopsKeyManager, auditLog domain.AuditLog) *KeyBackupCmd {
+	return &KeyBackupCmd{skm: skm, auditLog: auditLog}
+}
+
+func (k KeyBackupCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().StringSlice(toFlagName, nil, "Age public key (age1...) to encrypt the backup to, can be repeated")
+	cmd.Flags().StringP(outputFlagName, "o", "", "File to write the encrypted backup to")
+	cmd.Flags().Bool(forceFlagName, false, "Overwrite the output file if it exists")
+	_ = cmd.MarkFlagRequired(toFlagName)
+	_ = cmd.MarkFlagRequired(outputFlagName)
+}
+
+func (k KeyBackupCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	recipients, err := cmd.Flags().GetStringSlice(toFlagName)
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.Flags().GetString(outputFlagName)
+	if err != nil {
+		return nil, err
+	}
+	force, err := cmd.Flags().GetBool(forceFlagName)
+	if err != nil {
+		return nil, err
+	}
+	output, err = filepath.Abs(output)
+	if err != nil {
+		return nil, err
+	}
+	k.options = NewKeyBackupCmdOptions(recipients, output, force, args)
+	return k, nil
+}
+
+func (k KeyBackupCmd) Execute() (string, error) {
+	if _, err := os.Stat(k.options.OutputFile); err == nil && !k.options.Force {
+		return "", fmt.Errorf("%s already exists, use --%s to overwrite it", k.options.OutputFile, forceFlagName)
+	}
+
+	contexts := k.options.ClusterNames
+	if len(contexts) == 0 {
+		keys, err := k.skm.ListContextsWithKeys()
+		if err != nil {
+			return "", fmt.Errorf("list keys: %w", err)
+		}
+		if len(keys) == 0 {
+			return color.YellowString("No SOPS keys found."), nil
+		}
+		contexts = keys
+	}
+	sort.Strings(contexts)
+
+	bundle := &domain.KeyBundle{CreatedAt: time.Now().UTC(), Contexts: map[string]domain.CTX{}}
+	for _, ctxName := range contexts {
+		ctx, err := k.skm.ExportCtx(ctxName)
+		if err != nil {
+			k.auditLog.Record(domain.NewAuditEntry(domain.KeyBackup, ctxName, k.options.OutputFile, nil, err))
+			return "", err
+		}
+		bundle.Contexts[ctxName] = *ctx
+	}
+
+	data, err := key.SealKeyBundle(bundle, k.options.Recipients)
+	if err == nil {
+		err = file.AtomicWriteFile(k.options.OutputFile, data)
+	}
+	for _, ctxName := range contexts {
+		k.auditLog.Record(domain.NewAuditEntry(domain.KeyBackup, ctxName, k.options.OutputFile, nil, err))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	output := fmt.Sprintf("Backed up %d SOPS key(s) to %s:\n", len(contexts), color.GreenString(k.options.OutputFile))
+	for _, ctxName := range contexts {
+		output += "- " + color.CyanString(ctxName) + "\n"
+	}
+	return output, nil
+}
@@ -0,0 +1,11 @@
+package group
+
+type KeyGroupCmdOptions struct {
+	Name    string
+	Members []string
+	Delete  bool
+}
+
+func NewKeyGroupCmdOptions(name string, members []string, deleteGroup bool) *KeyGroupCmdOptions {
+	return &KeyGroupCmdOptions{Name: name, Members: members, Delete: deleteGroup}
+}
@@ -0,0 +1,130 @@
+package group
+
+import (
+	"fmt"
+	"slices"
+	"sopsctl/pkg/domain"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	setFlagName    = "set"
+	deleteFlagName = "delete"
+)
+
+type KeyGroupCmd struct {
+	options *KeyGroupCmdOptions
+	storage domain.KeyStorage
+}
+
+func NewKeyGroupCmd(storage domain.KeyStorage) *KeyGroupCmd {
+	return &KeyGroupCmd{storage: storage}
+}
+
+func (k KeyGroupCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().StringSlice(setFlagName, nil, "Contexts that belong to the group, replacing its current members")
+	cmd.Flags().Bool(deleteFlagName, false, "Delete the group")
+	cmd.MarkFlagsMutuallyExclusive(setFlagName, deleteFlagName)
+	cmd.Args = cobra.MaximumNArgs(1)
+}
+
+func (k KeyGroupCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	members, err := cmd.Flags().GetStringSlice(setFlagName)
+	if err != nil {
+		return nil, err
+	}
+	deleteGroup, err := cmd.Flags().GetBool(deleteFlagName)
+	if err != nil {
+		return nil, err
+	}
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if name == "" && (len(members) > 0 || deleteGroup) {
+		return nil, fmt.Errorf("a group name is required with --%s and --%s", setFlagName, deleteFlagName)
+	}
+	if strings.Contains(name, ",") {
+		return nil, fmt.Errorf("group name %q must not contain a comma", name)
+	}
+	k.options = NewKeyGroupCmdOptions(name, members, deleteGroup)
+	return k, nil
+}
+
+func (k KeyGroupCmd) Execute() (string, error) {
+	switch {
+	case k.options.Delete:
+		return k.deleteGroup()
+	case len(k.options.Members) > 0:
+		return k.setGroup()
+	default:
+		return k.listGroups()
+	}
+}
+
+func (k KeyGroupCmd) setGroup() (string, error) {
+	contexts, err := k.storage.ListContextsWithKeys()
+	if err != nil {
+		return "", err
+	}
+	if slices.Contains(contexts, k.options.Name) {
+		return "", fmt.Errorf("%s is the name of a stored context and cannot be used as a group name", k.options.Name)
+	}
+	var output string
+	for _, member := range k.options.Members {
+		if !slices.Contains(contexts, member) {
+			output += color.YellowString("Warning: no SOPS key is stored for context %s yet\n", member)
+		}
+	}
+	err = k.storage.SetClusterGroup(k.options.Name, k.options.Members)
+	if err != nil {
+		return "", err
+	}
+	return output + fmt.Sprintf("Cluster group %s set to: %s", color.CyanString(k.options.Name), strings.Join(k.options.Members, ", ")), nil
+}
+
+func (k KeyGroupCmd) deleteGroup() (string, error) {
+	groups, err := k.storage.GetClusterGroups()
+	if err != nil {
+		return "", err
+	}
+	if _, exists := groups[k.options.Name]; !exists {
+		return "", fmt.Errorf("cluster group %s does not exist", k.options.Name)
+	}
+	err = k.storage.SetClusterGroup(k.options.Name, nil)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Cluster group %s deleted", color.CyanString(k.options.Name)), nil
+}
+
+func (k KeyGroupCmd) listGroups() (string, error) {
+	groups, err := k.storage.GetClusterGroups()
+	if err != nil {
+		return "", err
+	}
+	if k.options.Name != "" {
+		members, exists := groups[k.options.Name]
+		if !exists {
+			return "", fmt.Errorf("cluster group %s does not exist", k.options.Name)
+		}
+		groups = map[string][]string{k.options.Name: members}
+	}
+	if len(groups) == 0 {
+		return color.YellowString("No cluster groups defined."), nil
+	}
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	output := "Cluster groups:\n"
+	for _, name := range names {
+		output += "- " + color.CyanString(name) + ": " + strings.Join(groups[name], ", ") + "\n"
+	}
+	return output, nil
+}
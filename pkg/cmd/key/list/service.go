@@ -3,6 +3,7 @@ package list
 import (
 	"fmt"
 	"sopsctl/pkg/domain"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -43,6 +44,9 @@ func (k KeyListCmd) Execute() (string, error) {
 	output := color.GreenString("SOPS Keys found for contexts:\n")
 	for _, ctx := range keys {
 		output += "- " + color.CyanString(ctx) + ": "
+		if encryptOnly, _ := k.skm.IsEncryptOnly(ctx); encryptOnly {
+			output += color.YellowString("(encrypt-only)")
+		}
 		publicKey, _ := k.skm.GetPublicKey(ctx)
 		privateKey, _ := k.skm.GetPrivateKey(ctx)
 		output += "\n  "
@@ -52,6 +56,17 @@ func (k KeyListCmd) Execute() (string, error) {
 			output += " Public Key: " + color.RedString("<not set>")
 		}
 
+		output += "\n  "
+		storageMode, err := k.skm.GetStorageMode(ctx)
+		if err != nil {
+			output += " Storage Mode: " + color.RedString("<unknown>")
+		} else {
+			output += " Storage Mode: " + color.GreenString(storageMode.ToString())
+		}
+		if storedCtx, err := k.skm.GetCtx(ctx); err == nil {
+			output += describeSource(storedCtx)
+		}
+
 		if k.options.ShowSensitive {
 			output += "\n  "
 			if privateKey != "" {
@@ -65,3 +80,38 @@ func (k KeyListCmd) Execute() (string, error) {
 	return output, nil
 
 }
+
+// describeSource renders where the stored key came from, when it was added and when it
+// was last refreshed.
+func describeSource(ctx *domain.CTX) string {
+	var output string
+	if ctx.Source == domain.SharedKeySource.ToString() {
+		output += "\n  "
+		output += " Source: " + color.GreenString("shared")
+		if ctx.SharedBy != "" {
+			output += " by " + color.GreenString(ctx.SharedBy)
+		}
+		if ctx.HasClusterReference() {
+			output += " from " + color.GreenString(ctx.Namespace+"/"+ctx.SecretName) + ":(" + color.GreenString(ctx.KeyName) + ")"
+		}
+	} else if ctx.HasClusterReference() {
+		output += "\n  "
+		output += " Source: " + color.GreenString(ctx.Namespace+"/"+ctx.SecretName) + ":(" + color.GreenString(ctx.KeyName) + ")"
+	} else if ctx.Source != "" {
+		output += "\n  "
+		output += " Source: " + color.GreenString(ctx.Source)
+	}
+	if ctx.Server != "" {
+		output += "\n  "
+		output += " Cluster: " + color.GreenString(ctx.Server)
+	}
+	if !ctx.AddedAt.IsZero() {
+		output += "\n  "
+		output += " Added: " + color.GreenString(ctx.AddedAt.Local().Format(time.RFC3339))
+	}
+	if !ctx.RefreshedAt.IsZero() {
+		output += "\n  "
+		output += " Refreshed: " + color.GreenString(ctx.RefreshedAt.Local().Format(time.RFC3339))
+	}
+	return output
+}
@@ -0,0 +1,17 @@
+package prune
+
+import "io"
+
+type KeyPruneCmdOptions struct {
+	Yes                bool
+	DryRun             bool
+	IncludeEncryptOnly bool
+
+	// In and Out are used to ask for confirmation before removing keys.
+	In  io.Reader
+	Out io.Writer
+}
+
+func NewKeyPruneCmdOptions(yes bool, dryRun bool, includeEncryptOnly bool, in io.Reader, out io.Writer) *KeyPruneCmdOptions {
+	return &KeyPruneCmdOptions{Yes: yes, DryRun: dryRun, IncludeEncryptOnly: includeEncryptOnly, In: in, Out: out}
+}
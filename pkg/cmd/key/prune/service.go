@@ -0,0 +1,157 @@
+package prune
+
+import (
+	"bufio"
+	"fmt"
+	"slices"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/helpers"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	yesFlagName                = "yes"
+	dryRunFlagName             = "dry-run"
+	includeEncryptOnlyFlagName = "include-encrypt-only"
+)
+
+type KeyPruneCmd struct {
+	options  *KeyPruneCmdOptions
+	skm      domain.SopsKeyManager
+	auditLog domain.AuditLog
+	// listKubeContexts and getClusterIdentity are variables to allow replacing the
+	// kubeconfig in tests
+	listKubeContexts   func() ([]string, error)
+	getClusterIdentity func(ctxName string) (*domain.ClusterIdentity, error)
+}
+
+func NewKeyPruneCmd(skm domain.SopsKeyManager, auditLog domain.AuditLog) *KeyPruneCmd {
+	return &KeyPruneCmd{skm: skm, auditLog: auditLog, listKubeContexts: helpers.ListKubeContexts, getClusterIdentity: helpers.GetClusterIdentity}
+}
+
+func (k KeyPruneCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().BoolP(yesFlagName, "y", false, "Remove orphaned keys without asking for confirmation")
+	cmd.Flags().Bool(dryRunFlagName, false, "Only list the orphaned keys that would be removed")
+	cmd.Flags().Bool(includeEncryptOnlyFlagName, false, "Also prune encrypt-only contexts missing from kubeconfig")
+	cmd.Args = cobra.NoArgs
+}
+
+func (k KeyPruneCmd) UseOptions(cmd *cobra.Command, _ []string) (domain.CommandExecutor, error) {
+	yes, err := cmd.Flags().GetBool(yesFlagName)
+	if err != nil {
+		return nil, err
+	}
+	dryRun, err := cmd.Flags().GetBool(dryRunFlagName)
+	if err != nil {
+		return nil, err
+	}
+	includeEncryptOnly, err := cmd.Flags().GetBool(includeEncryptOnlyFlagName)
+	if err != nil {
+		return nil, err
+	}
+	k.options = NewKeyPruneCmdOptions(yes, dryRun, includeEncryptOnly, cmd.InOrStdin(), cmd.OutOrStdout())
+	return k, nil
+}
+
+func (k KeyPruneCmd) Execute() (string, error) {
+	orphans, err := k.findOrphans()
+	if err != nil {
+		return "", err
+	}
+	if len(orphans) == 0 {
+		return color.GreenString("No orphaned SOPS keys found."), nil
+	}
+
+	listing := color.YellowString("SOPS keys for contexts not found in kubeconfig:\n")
+	for _, ctx := range orphans {
+		listing += "- " + color.CyanString(ctx) + "\n"
+	}
+	if k.options.DryRun {
+		return listing + "Dry run, no keys were removed.", nil
+	}
+	if !k.options.Yes {
+		_, _ = fmt.Fprint(k.options.Out, listing)
+		confirmed, err := k.confirm(fmt.Sprintf("Remove %d SOPS key(s)? [y/N]: ", len(orphans)))
+		if err != nil {
+			return "", err
+		}
+		if !confirmed {
+			return "Aborted, no keys were removed.", nil
+		}
+		listing = ""
+	}
+
+	output := listing
+	for _, ctx := range orphans {
+		err := k.skm.RemoveKeyForContext(ctx)
+		k.auditLog.Record(domain.NewAuditEntry(domain.KeyPrune, ctx, "", nil, err))
+		if err != nil {
+			return "", fmt.Errorf("failed to remove SOPS key for context %s: %w", ctx, err)
+		}
+		output += fmt.Sprintf("Removed SOPS key for context: %s\n", color.CyanString(ctx))
+	}
+	return output, nil
+}
+
+// findOrphans returns the sorted stored contexts that are missing from the kubeconfig.
+// A stored context recorded for a cluster that a kubeconfig context of another name
+// points at is still in use, since the key is resolved through that cluster. A kubeconfig without contexts is refused, since it is far more likely missing or the
+// wrong KUBECONFIG than a reason to treat every stored key as orphaned.
+func (k KeyPruneCmd) findOrphans() ([]string, error) {
+	kubeContexts, err := k.listKubeContexts()
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+	if len(kubeContexts) == 0 {
+		return nil, fmt.Errorf("the kubeconfig has no contexts, refusing to prune since every stored key would be removed, check KUBECONFIG")
+	}
+	keys, err := k.skm.ListContextsWithKeys()
+	if err != nil {
+		return nil, fmt.Errorf("list keys: %w", err)
+	}
+	var clusters []domain.ClusterIdentity
+	for _, kubeCtx := range kubeContexts {
+		if identity, err := k.getClusterIdentity(kubeCtx); err == nil {
+			clusters = append(clusters, *identity)
+		}
+	}
+	var orphans []string
+	for _, ctx := range keys {
+		if slices.Contains(kubeContexts, ctx) {
+			continue
+		}
+		stored, err := k.skm.GetCtx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if identity := stored.ClusterIdentity(); !identity.IsZero() && slices.ContainsFunc(clusters, identity.Matches) {
+			continue
+		}
+		if !k.options.IncludeEncryptOnly {
+			encryptOnly, err := k.skm.IsEncryptOnly(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if encryptOnly {
+				continue
+			}
+		}
+		orphans = append(orphans, ctx)
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+func (k KeyPruneCmd) confirm(prompt string) (bool, error) {
+	_, _ = fmt.Fprint(k.options.Out, prompt)
+	answer, err := bufio.NewReader(k.options.In).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, nil
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,133 @@
+package prune
+
+import (
+	"bytes"
+	"fmt"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/storage"
+	"sopsctl/pkg/services/testutil"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPruneCmd(t *testing.T, kubeContexts []string, options *KeyPruneCmdOptions) (*KeyPruneCmd, domain.SopsKeyManager) {
+	skm := testutil.NewKeyManager(t, "dev", "torn-down", "prod")
+	cmd := NewKeyPruneCmd(skm, testutil.NopAuditLog{})
+	cmd.listKubeContexts = func() ([]string, error) {
+		return kubeContexts, nil
+	}
+	cmd.getClusterIdentity = func(ctxName string) (*domain.ClusterIdentity, error) {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", ctxName)
+	}
+	cmd.options = options
+	return cmd, skm
+}
+
+func TestKeyPruneCmd_DryRunRemovesNothing(t *testing.T) {
+	uut, skm := newTestPruneCmd(t, []string{"dev"}, NewKeyPruneCmdOptions(false, true, true, nil, nil))
+
+	result, err := uut.Execute()
+	require.NoError(t, err)
+	assert.Contains(t, result, "torn-down")
+	assert.Contains(t, result, "prod")
+
+	contexts, err := skm.ListContextsWithKeys()
+	require.NoError(t, err)
+	assert.Len(t, contexts, 3)
+}
+
+func TestKeyPruneCmd_RemovesOrphansAfterConfirmation(t *testing.T) {
+	out := &bytes.Buffer{}
+	options := NewKeyPruneCmdOptions(false, false, true, strings.NewReader("y\n"), out)
+	uut, skm := newTestPruneCmd(t, []string{"dev", "prod"}, options)
+
+	_, err := uut.Execute()
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "torn-down")
+
+	contexts, err := skm.ListContextsWithKeys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dev", "prod"}, contexts)
+}
+
+func TestKeyPruneCmd_DeclinedConfirmationRemovesNothing(t *testing.T) {
+	options := NewKeyPruneCmdOptions(false, false, true, strings.NewReader("\n"), &bytes.Buffer{})
+	uut, skm := newTestPruneCmd(t, []string{"dev"}, options)
+
+	_, err := uut.Execute()
+	require.NoError(t, err)
+
+	contexts, err := skm.ListContextsWithKeys()
+	require.NoError(t, err)
+	assert.Len(t, contexts, 3)
+}
+
+func TestKeyPruneCmd_KeepsEncryptOnlyContextsByDefault(t *testing.T) {
+	uut, skm := newTestPruneCmd(t, []string{"dev"}, NewKeyPruneCmdOptions(true, false, false, nil, nil))
+
+	_, err := uut.Execute()
+	require.NoError(t, err)
+
+	contexts, err := skm.ListContextsWithKeys()
+	require.NoError(t, err)
+	assert.Len(t, contexts, 3)
+}
+
+func TestKeyPruneCmd_RemovesOrphanedPrivateKeysByDefault(t *testing.T) {
+	uut, skm := newTestPruneCmd(t, []string{"dev"}, NewKeyPruneCmdOptions(true, false, false, nil, nil))
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, storage.NewLocalUserKeyStorageService().SavePrivateKey(identity.String(), "staging"))
+
+	result, err := uut.Execute()
+	require.NoError(t, err)
+	assert.Contains(t, result, "staging")
+
+	contexts, err := skm.ListContextsWithKeys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dev", "torn-down", "prod"}, contexts)
+}
+
+func TestKeyPruneCmd_RefusesKubeconfigWithoutContexts(t *testing.T) {
+	uut, skm := newTestPruneCmd(t, nil, NewKeyPruneCmdOptions(true, false, true, nil, nil))
+
+	_, err := uut.Execute()
+	assert.ErrorContains(t, err, "no contexts")
+
+	contexts, err := skm.ListContextsWithKeys()
+	require.NoError(t, err)
+	assert.Len(t, contexts, 3)
+}
+
+func TestKeyPruneCmd_KeepsKeysReachedThroughAnAliasedContext(t *testing.T) {
+	uut, skm := newTestPruneCmd(t, []string{"dev", "prod-eks"}, NewKeyPruneCmdOptions(true, false, false, nil, nil))
+	uut.getClusterIdentity = func(ctxName string) (*domain.ClusterIdentity, error) {
+		if ctxName != "prod-eks" {
+			return nil, fmt.Errorf("context %q not found in kubeconfig", ctxName)
+		}
+		return &domain.ClusterIdentity{Server: "https://10.0.0.1", CAFingerprint: "ab12"}, nil
+	}
+	keyStorage := storage.NewLocalUserKeyStorageService()
+	for ctx, identity := range map[string]domain.ClusterIdentity{
+		"eu":       {Server: "https://eu.example.com", CAFingerprint: "ab12"},
+		"decommed": {Server: "https://old.example.com", CAFingerprint: "cd34"},
+	} {
+		privateKey, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		require.NoError(t, keyStorage.SavePrivateKey(privateKey.String(), ctx))
+		require.NoError(t, keyStorage.SetCtxClusterIdentity(ctx, identity))
+	}
+
+	result, err := uut.Execute()
+	require.NoError(t, err)
+	assert.Contains(t, result, "decommed")
+	assert.NotContains(t, result, "eu")
+
+	contexts, err := skm.ListContextsWithKeys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dev", "torn-down", "prod", "eu"}, contexts)
+}
@@ -0,0 +1,16 @@
+package receive
+
+import "io"
+
+type KeyReceiveCmdOptions struct {
+	// SharedFile is the file holding the shared key, "-" reads it from In.
+	SharedFile    string
+	IdentityFiles []string
+	ClusterName   string
+	Force         bool
+	In            io.Reader
+}
+
+func NewKeyReceiveCmdOptions(sharedFile string, identityFiles []string, clusterName string, force bool, in io.Reader) *KeyReceiveCmdOptions {
+	return &KeyReceiveCmdOptions{SharedFile: sharedFile, IdentityFiles: identityFiles, ClusterName: clusterName, Force: force, In: in}
+}
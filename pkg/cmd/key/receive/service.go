@@ -0,0 +1,128 @@
+package receive
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/key"
+	"time"
+
+	"filippo.io/age"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	identityFlagName = "identity"
+	asFlagName       = "as"
+	forceFlagName    = "force"
+)
+
+type KeyReceiveCmd struct {
+	options  *KeyReceiveCmdOptions
+	skm      domain.SopsKeyManager
+	auditLog domain.AuditLog
+}
+
+func NewKeyReceiveCmd(skm domain.SopsKeyManager, auditLog domain.AuditLog) *KeyReceiveCmd {
+	return &KeyReceiveCmd{skm: skm, auditLog: auditLog}
+}
+
+func (k KeyReceiveCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().StringSliceP(identityFlagName, "i", nil, "Age identity file holding the private key the key was shared with, can be repeated")
+	cmd.Flags().String(asFlagName, "", "Store the key under this context name instead of the name it was shared under")
+	cmd.Flags().Bool(forceFlagName, false, "Overwrite a key already stored for the context")
+	_ = cmd.MarkFlagRequired(identityFlagName)
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+func (k KeyReceiveCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	identityFiles, err := cmd.Flags().GetStringSlice(identityFlagName)
+	if err != nil {
+		return nil, err
+	}
+	clusterName, err := cmd.Flags().GetString(asFlagName)
+	if err != nil {
+		return nil, err
+	}
+	force, err := cmd.Flags().GetBool(forceFlagName)
+	if err != nil {
+		return nil, err
+	}
+	k.options = NewKeyReceiveCmdOptions(args[0], identityFiles, clusterName, force, cmd.InOrStdin())
+	return k, nil
+}
+
+func (k KeyReceiveCmd) Execute() (string, error) {
+	bundle, err := k.readBundle()
+	if err != nil {
+		return "", err
+	}
+	if len(bundle.Contexts) != 1 {
+		return "", fmt.Errorf("expected a single shared key but found %d, use 'sopsctl key restore' for backups", len(bundle.Contexts))
+	}
+	var sharedName string
+	var ctx domain.CTX
+	for name, shared := range bundle.Contexts {
+		sharedName, ctx = name, shared
+	}
+	ctxName := sharedName
+	if k.options.ClusterName != "" {
+		ctxName = k.options.ClusterName
+	}
+
+	existing, err := k.skm.ListContextsWithKeys()
+	if err != nil {
+		return "", fmt.Errorf("list keys: %w", err)
+	}
+	if slices.Contains(existing, ctxName) && !k.options.Force {
+		return "", fmt.Errorf("a key is already stored for context %s, use --%s to overwrite it or --%s to store it under another name", ctxName, forceFlagName, asFlagName)
+	}
+
+	ctx.Source = domain.SharedKeySource.ToString()
+	ctx.SharedBy = bundle.CreatedBy
+	ctx.AddedAt = time.Now().UTC()
+	ctx.StorageMode = domain.LocalStorageMode.ToString()
+	err = k.skm.ImportCtx(ctxName, ctx)
+	k.auditLog.Record(domain.NewAuditEntry(domain.KeyReceive, ctxName, k.sharedFilePath(), nil, err))
+	if err != nil {
+		return "", err
+	}
+
+	output := "Received SOPS key for context: " + color.GreenString(ctxName)
+	if bundle.CreatedBy != "" {
+		output += " (shared by " + color.GreenString(bundle.CreatedBy) + ")"
+	}
+	return output, nil
+}
+
+func (k KeyReceiveCmd) readBundle() (*domain.KeyBundle, error) {
+	var identities []age.Identity
+	for _, identityFile := range k.options.IdentityFiles {
+		fileIdentities, err := key.ReadIdentityFile(identityFile)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, fileIdentities...)
+	}
+	var data []byte
+	var err error
+	if k.options.SharedFile == "-" {
+		data, err = io.ReadAll(k.options.In)
+	} else {
+		data, err = os.ReadFile(k.options.SharedFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read shared key: %w", err)
+	}
+	return key.OpenKeyBundle(data, identities)
+}
+
+func (k KeyReceiveCmd) sharedFilePath() string {
+	if k.options.SharedFile == "-" {
+		return ""
+	}
+	return k.options.SharedFile
+}
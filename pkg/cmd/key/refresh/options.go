@@ -0,0 +1,10 @@
+package refresh
+
+type KeyRefreshCmdOptions struct {
+	RefreshAll   bool
+	ClusterNames []string
+}
+
+func NewKeyRefreshCmdOptions(refreshAll bool, clusterNames []string) *KeyRefreshCmdOptions {
+	return &KeyRefreshCmdOptions{RefreshAll: refreshAll, ClusterNames: clusterNames}
+}
@@ -0,0 +1,93 @@
+package refresh
+
+import (
+	"fmt"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/utils"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+type KeyRefreshCmd struct {
+	options  *KeyRefreshCmdOptions
+	skm      domain.SopsKeyManager
+	auditLog domain.AuditLog
+}
+
+func NewKeyRefreshCmd(skm domain.SopsKeyManager, auditLog domain.AuditLog) *KeyRefreshCmd {
+	return &KeyRefreshCmd{skm: skm, auditLog: auditLog}
+}
+
+func (k KeyRefreshCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().Bool("all", false, "Refresh the keys of all stored contexts")
+	cmd.Args = cobra.MaximumNArgs(1)
+}
+
+func (k KeyRefreshCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	refreshAll, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return nil, err
+	}
+	if refreshAll && len(args) > 0 {
+		return nil, fmt.Errorf("--all cannot be combined with a context name")
+	}
+	clusterNames := args
+	if !refreshAll && len(args) == 0 {
+		gFlags, err := utils.UseGlobalFlags(cmd)
+		if err != nil {
+			return nil, err
+		}
+		clusterNames = []string{gFlags.Cluster}
+	}
+	k.options = NewKeyRefreshCmdOptions(refreshAll, clusterNames)
+	return k, nil
+}
+
+func (k KeyRefreshCmd) Execute() (string, error) {
+	contexts := k.options.ClusterNames
+	if k.options.RefreshAll {
+		keys, err := k.skm.ListContextsWithKeys()
+		if err != nil {
+			return "", fmt.Errorf("list keys: %w", err)
+		}
+		if len(keys) == 0 {
+			return color.YellowString("No SOPS keys found."), nil
+		}
+		sort.Strings(keys)
+		contexts = keys
+	}
+
+	var output string
+	for _, ctx := range contexts {
+		status, err := k.skm.RefreshKey(ctx)
+		if status != domain.KeyRefreshUnchanged {
+			k.auditLog.Record(domain.NewAuditEntry(domain.KeyRefresh, ctx, "", nil, err))
+		}
+		if err != nil {
+			if !k.options.RefreshAll {
+				return "", fmt.Errorf("failed to refresh SOPS key for context %s: %w", ctx, err)
+			}
+			output += fmt.Sprintf("Failed to refresh SOPS key for context %s: %s\n", color.CyanString(ctx), color.RedString(err.Error()))
+			continue
+		}
+		output += describeStatus(ctx, status) + "\n"
+	}
+	return output, nil
+}
+
+func describeStatus(ctx string, status domain.KeyRefreshStatus) string {
+	switch status {
+	case domain.KeyRefreshChanged:
+		return fmt.Sprintf("Refreshed SOPS key for context %s: %s", color.CyanString(ctx), color.GreenString("key changed"))
+	case domain.KeyRefreshUnchanged:
+		return fmt.Sprintf("SOPS key for context %s is up to date", color.CyanString(ctx))
+	case domain.KeyRefreshLive:
+		return fmt.Sprintf("Skipped context %s: cluster storage mode reads the key live", color.CyanString(ctx))
+	case domain.KeyRefreshEncryptOnly:
+		return fmt.Sprintf("Skipped context %s: encrypt-only context has no source to refresh from", color.CyanString(ctx))
+	default:
+		return fmt.Sprintf("Skipped context %s: %s", color.CyanString(ctx), color.YellowString("no source recorded, run add-key again"))
+	}
+}
@@ -10,8 +10,9 @@ import (
 )
 
 type KeyRemoveCmd struct {
-	options *KeyRemoveCmdOptions
-	skm     domain.SopsKeyManager
+	options  *KeyRemoveCmdOptions
+	skm      domain.SopsKeyManager
+	auditLog domain.AuditLog
 }
 
 func (k KeyRemoveCmd) InitCmd(cmd *cobra.Command) {
@@ -20,8 +21,8 @@ func (k KeyRemoveCmd) InitCmd(cmd *cobra.Command) {
 	cmd.Use = "remove [cluster-name]"
 }
 
-func NewKeyRemoveCmd(skm domain.SopsKeyManager) *KeyRemoveCmd {
-	return &KeyRemoveCmd{skm: skm}
+func NewKeyRemoveCmd(skm domain.SopsKeyManager, auditLog domain.AuditLog) *KeyRemoveCmd {
+	return &KeyRemoveCmd{skm: skm, auditLog: auditLog}
 }
 
 func (k KeyRemoveCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
@@ -47,6 +48,7 @@ func (k KeyRemoveCmd) Execute() (string, error) {
 		isInArgs := slices.Contains(k.options.ClusterNames, ctx)
 		if k.options.RemoveAll || isInArgs {
 			err := k.skm.RemoveKeyForContext(ctx)
+			k.auditLog.Record(domain.NewAuditEntry(domain.KeyRemove, ctx, "", nil, err))
 			if err != nil {
 				return "", fmt.Errorf("failed to remove SOPS key for context %s: %w", ctx, err)
 			}
@@ -0,0 +1,12 @@
+package restore
+
+type KeyRestoreCmdOptions struct {
+	BackupFile    string
+	IdentityFiles []string
+	Force         bool
+	ClusterNames  []string
+}
+
+func NewKeyRestoreCmdOptions(backupFile string, identityFiles []string, force bool, clusterNames []string) *KeyRestoreCmdOptions {
+	return &KeyRestoreCmdOptions{BackupFile: backupFile, IdentityFiles: identityFiles, Force: force, ClusterNames: clusterNames}
+}
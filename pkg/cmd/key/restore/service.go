@@ -0,0 +1,104 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/key"
+	"sopsctl/pkg/services/utils"
+	"sort"
+
+	"filippo.io/age"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	identityFlagName = "identity"
+	forceFlagName    = "force"
+)
+
+type KeyRestoreCmd struct {
+	options  *KeyRestoreCmdOptions
+	skm      domain.SopsKeyManager
+	auditLog domain.AuditLog
+}
+
+func NewKeyRestoreCmd(skm domain.SopsKeyManager, auditLog domain.AuditLog) *KeyRestoreCmd {
+	return &KeyRestoreCmd{skm: skm, auditLog: auditLog}
+}
+
+func (k KeyRestoreCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().StringSliceP(identityFlagName, "i", nil, "Age identity file holding the private key the backup was encrypted to, can be repeated")
+	cmd.Flags().Bool(forceFlagName, false, "Overwrite keys already stored for a context")
+	_ = cmd.MarkFlagRequired(identityFlagName)
+	cmd.Args = cobra.MinimumNArgs(1)
+}
+
+func (k KeyRestoreCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	backupFile, err := utils.UserFileArg(args)
+	if err != nil {
+		return nil, err
+	}
+	identityFiles, err := cmd.Flags().GetStringSlice(identityFlagName)
+	if err != nil {
+		return nil, err
+	}
+	force, err := cmd.Flags().GetBool(forceFlagName)
+	if err != nil {
+		return nil, err
+	}
+	k.options = NewKeyRestoreCmdOptions(backupFile, identityFiles, force, args[1:])
+	return k, nil
+}
+
+func (k KeyRestoreCmd) Execute() (string, error) {
+	var identities []age.Identity
+	for _, identityFile := range k.options.IdentityFiles {
+		fileIdentities, err := key.ReadIdentityFile(identityFile)
+		if err != nil {
+			return "", err
+		}
+		identities = append(identities, fileIdentities...)
+	}
+	data, err := os.ReadFile(k.options.BackupFile)
+	if err != nil {
+		return "", fmt.Errorf("read backup: %w", err)
+	}
+	bundle, err := key.OpenKeyBundle(data, identities)
+	if err != nil {
+		return "", err
+	}
+
+	contexts := k.options.ClusterNames
+	if len(contexts) == 0 {
+		for ctxName := range bundle.Contexts {
+			contexts = append(contexts, ctxName)
+		}
+	}
+	sort.Strings(contexts)
+	existing, err := k.skm.ListContextsWithKeys()
+	if err != nil {
+		return "", fmt.Errorf("list keys: %w", err)
+	}
+
+	var output string
+	for _, ctxName := range contexts {
+		ctx, ok := bundle.Contexts[ctxName]
+		if !ok {
+			return "", fmt.Errorf("backup does not contain a key for context %s", ctxName)
+		}
+		if slices.Contains(existing, ctxName) && !k.options.Force {
+			output += fmt.Sprintf("Skipped context %s: %s\n", color.CyanString(ctxName), color.YellowString("a key is already stored, use --force to overwrite it"))
+			continue
+		}
+		err := k.skm.ImportCtx(ctxName, ctx)
+		k.auditLog.Record(domain.NewAuditEntry(domain.KeyRestore, ctxName, k.options.BackupFile, nil, err))
+		if err != nil {
+			return "", fmt.Errorf("failed to restore SOPS key for context %s: %w", ctxName, err)
+		}
+		output += fmt.Sprintf("Restored SOPS key for context: %s\n", color.CyanString(ctxName))
+	}
+	return output, nil
+}
@@ -0,0 +1,56 @@
+package settings
+
+import (
+	"fmt"
+	"sopsctl/pkg/cmd/help"
+	"sopsctl/pkg/domain"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+const setSopsFallbackFlagName = "set-sops-fallback"
+
+type keySettingsCmdOptions struct {
+	SopsKeyFallback bool
+}
+
+type KeySettingsCmd struct {
+	options *keySettingsCmdOptions
+	storage domain.KeyStorage
+}
+
+func NewKeySettingsCmd(storage domain.KeyStorage) *KeySettingsCmd {
+	return &KeySettingsCmd{storage: storage}
+}
+
+func (k KeySettingsCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	fallbackStr, err := cmd.Flags().GetString(setSopsFallbackFlagName)
+	if err != nil {
+		return nil, err
+	}
+	if fallbackStr == "" {
+		return help.NewHelpExecutor(cmd), nil
+	}
+	fallback, err := strconv.ParseBool(fallbackStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for --%s: %s", setSopsFallbackFlagName, fallbackStr)
+	}
+	k.options = &keySettingsCmdOptions{SopsKeyFallback: fallback}
+	return k, nil
+}
+
+func (k KeySettingsCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().String(setSopsFallbackFlagName, "", "Also decrypt with keys from SOPS_AGE_KEY_FILE, SOPS_AGE_KEY and ~/.config/sops/age/keys.txt (true, false)")
+}
+
+func (k KeySettingsCmd) Execute() (string, error) {
+	err := k.storage.SetSopsKeyFallback(k.options.SopsKeyFallback)
+	if err != nil {
+		return "", err
+	}
+	if k.options.SopsKeyFallback {
+		return "fallback to the standard sops key locations enabled", nil
+	}
+	return "fallback to the standard sops key locations disabled", nil
+}
@@ -0,0 +1,12 @@
+package share
+
+type KeyShareCmdOptions struct {
+	Cluster    string
+	Recipients []string
+	OutputFile string
+	Force      bool
+}
+
+func NewKeyShareCmdOptions(cluster string, recipients []string, outputFile string, force bool) *KeyShareCmdOptions {
+	return &KeyShareCmdOptions{Cluster: cluster, Recipients: recipients, OutputFile: outputFile, Force: force}
+}
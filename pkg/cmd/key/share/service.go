@@ -0,0 +1,113 @@
+package share
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/file"
+	"sopsctl/pkg/services/helpers"
+	"sopsctl/pkg/services/key"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	toFlagName     = "to"
+	outputFlagName = "output"
+	forceFlagName  = "force"
+)
+
+type KeyShareCmd struct {
+	options  *KeyShareCmdOptions
+	skm      domain.SopsKeyManager
+	auditLog domain.AuditLog
+}
+
+func NewKeyShareCmd(skm domain.SopsKeyManager, auditLog domain.AuditLog) *KeyShareCmd {
+	return &KeyShareCmd{skm: skm, auditLog: auditLog}
+}
+
+func (k KeyShareCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().StringSlice(toFlagName, nil, "Age public key (age1...) of the teammate to share the key with, can be repeated")
+	cmd.Flags().StringP(outputFlagName, "o", "", "File to write the encrypted key to instead of stdout")
+	cmd.Flags().Bool(forceFlagName, false, "Overwrite the output file if it exists")
+	_ = cmd.MarkFlagRequired(toFlagName)
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+func (k KeyShareCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	recipients, err := cmd.Flags().GetStringSlice(toFlagName)
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.Flags().GetString(outputFlagName)
+	if err != nil {
+		return nil, err
+	}
+	force, err := cmd.Flags().GetBool(forceFlagName)
+	if err != nil {
+		return nil, err
+	}
+	if output != "" {
+		output, err = filepath.Abs(output)
+		if err != nil {
+			return nil, err
+		}
+	}
+	k.options = NewKeyShareCmdOptions(args[0], recipients, output, force)
+	return k, nil
+}
+
+func (k KeyShareCmd) Execute() (string, error) {
+	data, err := k.share()
+	k.auditLog.Record(domain.NewAuditEntry(domain.KeyShare, k.options.Cluster, k.options.OutputFile, nil, err))
+	if err != nil {
+		return "", err
+	}
+	if k.options.OutputFile == "" {
+		return string(data), nil
+	}
+	return fmt.Sprintf("Shared SOPS key for context %s in %s, hand it over with 'sopsctl key receive'", color.CyanString(k.options.Cluster), color.GreenString(k.options.OutputFile)), nil
+}
+
+// share returns the context's key encrypted to the recipients, and writes it to the
+// output file when one is given.
+func (k KeyShareCmd) share() ([]byte, error) {
+	if k.options.OutputFile != "" && !k.options.Force {
+		if _, err := os.Stat(k.options.OutputFile); err == nil {
+			return nil, fmt.Errorf("%s already exists, use --%s to overwrite it", k.options.OutputFile, forceFlagName)
+		}
+	}
+	encryptOnly, err := k.skm.IsEncryptOnly(k.options.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if encryptOnly {
+		return nil, fmt.Errorf("context %s is encrypt-only, share its public key instead", k.options.Cluster)
+	}
+	ctx, err := k.skm.ExportCtx(k.options.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	// the storage mode is a preference of the sharing user, not part of the key
+	ctx.StorageMode = ""
+
+	bundle := &domain.KeyBundle{
+		CreatedAt: time.Now().UTC(),
+		CreatedBy: helpers.CurrentUserName(),
+		Contexts:  map[string]domain.CTX{k.options.Cluster: *ctx},
+	}
+	data, err := key.SealKeyBundle(bundle, k.options.Recipients)
+	if err != nil {
+		return nil, err
+	}
+	if k.options.OutputFile != "" {
+		if err := file.AtomicWriteFile(k.options.OutputFile, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
@@ -8,10 +8,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const setStorageModeFlagName = "set-storage-mode"
+const (
+	setStorageModeFlagName   = "set-storage-mode"
+	unsetStorageModeFlagName = "unset-storage-mode"
+	clusterFlagName          = "cluster"
+)
 
 type keyRootCmdOptions struct {
 	StorageMode domain.StorageMode
+	// Cluster is the context the mode applies to; empty means the global default.
+	Cluster string
 }
 
 type KeyStorageModeCmd struct {
@@ -28,6 +34,27 @@ func (k KeyStorageModeCmd) UseOptions(cmd *cobra.Command, args []string) (domain
 	if err != nil {
 		return nil, err
 	}
+	unset, err := cmd.Flags().GetBool(unsetStorageModeFlagName)
+	if err != nil {
+		return nil, err
+	}
+	// The global --cluster flag is only honoured when given explicitly, since
+	// without it the mode is set globally rather than for the current context.
+	var cluster string
+	if cmd.Flags().Changed(clusterFlagName) {
+		cluster = cmd.Flags().Lookup(clusterFlagName).Value.String()
+	}
+
+	if unset {
+		if storageModeStr != "" {
+			return nil, fmt.Errorf("--%s cannot be combined with --%s", unsetStorageModeFlagName, setStorageModeFlagName)
+		}
+		if cluster == "" {
+			return nil, fmt.Errorf("--%s requires --%s", unsetStorageModeFlagName, clusterFlagName)
+		}
+		k.options = &keyRootCmdOptions{Cluster: cluster}
+		return k, nil
+	}
 	if storageModeStr == "" {
 		return help.NewHelpExecutor(cmd), nil
 	}
@@ -39,16 +66,21 @@ func (k KeyStorageModeCmd) UseOptions(cmd *cobra.Command, args []string) (domain
 
 	options := &keyRootCmdOptions{
 		StorageMode: sm,
+		Cluster:     cluster,
 	}
 	k.options = options // to be used in Execute
 	return k, nil
 }
 
 func (k KeyStorageModeCmd) InitCmd(cmd *cobra.Command) {
-	cmd.Flags().StringP(setStorageModeFlagName, "s", "", "Storage mode for SOPS keys (local, cluster.)")
+	cmd.Flags().StringP(setStorageModeFlagName, "s", "", "Storage mode for SOPS keys (local, cluster.) Applies to a single context when --cluster is given")
+	cmd.Flags().Bool(unsetStorageModeFlagName, false, "Remove the storage mode of the context given by --cluster so it follows the global default")
 }
 
 func (k KeyStorageModeCmd) Execute() (string, error) {
+	if k.options.Cluster != "" {
+		return k.setCtxStorageMode()
+	}
 	currentMode, err := k.storage.GetStorageMode()
 	if err != nil {
 		return "", err
@@ -62,3 +94,18 @@ func (k KeyStorageModeCmd) Execute() (string, error) {
 	}
 	return fmt.Sprintf("key storage mode set to %s", k.options.StorageMode.ToString()), nil
 }
+
+func (k KeyStorageModeCmd) setCtxStorageMode() (string, error) {
+	err := k.storage.SetCtxStorageMode(k.options.Cluster, k.options.StorageMode)
+	if err != nil {
+		return "", err
+	}
+	if k.options.StorageMode == "" {
+		effectiveMode, err := k.storage.GetCtxStorageMode(k.options.Cluster)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("key storage mode for context %s now follows the global default (%s)", k.options.Cluster, effectiveMode.ToString()), nil
+	}
+	return fmt.Sprintf("key storage mode for context %s set to %s", k.options.Cluster, k.options.StorageMode.ToString()), nil
+}
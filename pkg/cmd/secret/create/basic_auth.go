@@ -0,0 +1,177 @@
+package create
+
+import (
+	"fmt"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/generate"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// htpasswdKey is the key ingress-nginx reads the htpasswd file from
+const htpasswdKey = "auth"
+
+// SecretCreateBasicAuthCmd creates a secret with an htpasswd file under auth, as used by
+// the ingress-nginx auth-secret annotation.
+type SecretCreateBasicAuthCmd struct {
+	createPipeline
+
+	Users []string
+	// GeneratePasswords is a length[:charset] spec, the passwords are prompted for when empty
+	GeneratePasswords string
+	// PasswordsOutput is the file the plain text passwords are written to as a separate
+	// encrypted secret, they are only stored hashed when empty
+	PasswordsOutput string
+
+	passwordSpec generate.RandomSpec
+	passwords    map[string][]byte
+	// passwordsPipeline creates the secret with the plain text passwords
+	passwordsPipeline createPipeline
+	readPassword      passwordReader
+}
+
+func NewSecretCreateBasicAuthCmd(es domain.EncryptionService, skm domain.SopsKeyManager, auditLog domain.AuditLog) *SecretCreateBasicAuthCmd {
+	return &SecretCreateBasicAuthCmd{createPipeline: newCreatePipeline(es, skm, auditLog)}
+}
+
+func (s *SecretCreateBasicAuthCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&s.Users, "user", s.Users, "Add a user to the htpasswd file, can be repeated")
+	cmd.Flags().StringVar(&s.GeneratePasswords, "generate-passwords", s.GeneratePasswords, "Generate the passwords instead of prompting for them, optionally with a length[:alnum|hex|base64] spec")
+	cmd.Flags().Lookup("generate-passwords").NoOptDefVal = "24:alnum"
+	cmd.Flags().StringVar(&s.PasswordsOutput, "passwords-output", s.PasswordsOutput, "Also write the plain text passwords, keyed by user, to this file as a separate encrypted secret named NAME-passwords")
+	s.initCommonFlags(cmd)
+}
+
+func (s *SecretCreateBasicAuthCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	if err := s.useCommonOptions(cmd, args); err != nil {
+		return nil, err
+	}
+	s.Users, _ = cmd.Flags().GetStringArray("user")
+	s.GeneratePasswords, _ = cmd.Flags().GetString("generate-passwords")
+	s.PasswordsOutput, _ = cmd.Flags().GetString("passwords-output")
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	if s.PasswordsOutput != "" {
+		// The passwords secret shares the metadata of the htpasswd secret but is written
+		// to its own file and not added to the kustomization
+		s.passwordsPipeline = s.createPipeline
+		s.passwordsPipeline.Name = s.Name + "-passwords"
+		s.passwordsPipeline.Output = s.PasswordsOutput
+		s.passwordsPipeline.AddToKustomization = false
+		s.passwordsPipeline.kustomizationPath = ""
+		if err := s.passwordsPipeline.checkOutput(); err != nil {
+			return nil, err
+		}
+		if s.passwordsPipeline.Output == s.Output {
+			return nil, fmt.Errorf("--passwords-output must differ from --output")
+		}
+	}
+	if s.GeneratePasswords == "" {
+		read, err := terminalPasswordReader(s.IOStreams.In)
+		if err != nil {
+			return nil, fmt.Errorf("prompting for the passwords requires an interactive terminal, use --generate-passwords otherwise: %w", err)
+		}
+		s.readPassword = read
+	}
+	return s, nil
+}
+
+// Validate checks the users and that generated passwords are kept somewhere.
+func (s *SecretCreateBasicAuthCmd) Validate() error {
+	if len(s.Users) == 0 {
+		return fmt.Errorf("at least one --user is required")
+	}
+	seen := make(map[string]bool, len(s.Users))
+	for _, user := range s.Users {
+		if err := generate.ValidateHtpasswdUser(user); err != nil {
+			return err
+		}
+		if seen[user] {
+			return fmt.Errorf("user %s is given more than once", user)
+		}
+		seen[user] = true
+		if s.PasswordsOutput != "" {
+			if err := validateKeyName(user); err != nil {
+				return fmt.Errorf("user %s cannot be a key of the passwords secret: %w", user, err)
+			}
+		}
+	}
+	if s.GeneratePasswords != "" {
+		spec, err := generate.ParseRandomSpec(s.GeneratePasswords)
+		if err != nil {
+			return fmt.Errorf("invalid --generate-passwords %q: %w", s.GeneratePasswords, err)
+		}
+		s.passwordSpec = spec
+		if spec.EncodedLength() > generate.MaxHtpasswdPasswordLength {
+			return fmt.Errorf("--generate-passwords %q gives %d byte passwords, bcrypt only hashes up to %d bytes", s.GeneratePasswords, spec.EncodedLength(), generate.MaxHtpasswdPasswordLength)
+		}
+		if s.PasswordsOutput == "" {
+			return fmt.Errorf("generated passwords are only stored hashed, use --passwords-output to keep them")
+		}
+	}
+	return nil
+}
+
+func (s *SecretCreateBasicAuthCmd) Execute() (string, error) {
+	if s.PasswordsOutput == "" {
+		return s.execute(domain.SecretCreateBasicAuth, s.createSecretBasicAuth)
+	}
+	// Both secrets are encrypted before either is written and the passwords are written
+	// first, so a failure never leaves an htpasswd secret whose passwords are lost.
+	encrypted, dataKeys, err := s.create(s.createSecretBasicAuth)
+	if err != nil {
+		return s.write(domain.SecretCreateBasicAuth, encrypted, dataKeys, err)
+	}
+	encryptedPasswords, passwordKeys, err := s.passwordsPipeline.create(s.createSecretPasswords)
+	passwordsResult, err := s.passwordsPipeline.write(domain.SecretCreateBasicAuth, encryptedPasswords, passwordKeys, err)
+	if err != nil {
+		return passwordsResult, err
+	}
+	result, err := s.write(domain.SecretCreateBasicAuth, encrypted, dataKeys, nil)
+	if s.Output == "" && !s.DryRun {
+		// stdout holds the encrypted htpasswd secret
+		_, _ = fmt.Fprintln(s.IOStreams.ErrOut, passwordsResult)
+		return result, err
+	}
+	return result + "\n" + passwordsResult, err
+}
+
+func (s *SecretCreateBasicAuthCmd) createSecretBasicAuth() (*corev1.Secret, error) {
+	s.passwords = make(map[string][]byte, len(s.Users))
+	for _, user := range s.Users {
+		var password []byte
+		var err error
+		if s.GeneratePasswords != "" {
+			password, err = s.passwordSpec.Generate()
+		} else {
+			password, err = promptValue(user, s.readPassword, s.IOStreams.ErrOut)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// rejected here rather than in Htpasswd, before the next user is prompted for
+		if err := generate.ValidateHtpasswdPassword(user, password); err != nil {
+			return nil, err
+		}
+		s.passwords[user] = password
+	}
+	htpasswd, err := generate.Htpasswd(s.Users, s.passwords)
+	if err != nil {
+		return nil, err
+	}
+	secret := newSecretObj(s.Name, s.Namespace, corev1.SecretTypeOpaque)
+	secret.Data[htpasswdKey] = htpasswd
+	return secret, nil
+}
+
+// createSecretPasswords builds the secret with the plain text passwords once
+// createSecretBasicAuth has set them.
+func (s *SecretCreateBasicAuthCmd) createSecretPasswords() (*corev1.Secret, error) {
+	secret := newSecretObj(s.passwordsPipeline.Name, s.Namespace, corev1.SecretTypeOpaque)
+	for user, password := range s.passwords {
+		secret.Data[user] = password
+	}
+	return secret, nil
+}
@@ -0,0 +1,121 @@
+package create
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/services/encryption"
+	"sopsctl/pkg/services/testutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestSecretCreateBasicAuthCmd_Validate(t *testing.T) {
+	valid := []SecretCreateBasicAuthCmd{
+		{Users: []string{"alice", "bob"}},
+		{Users: []string{"alice"}, GeneratePasswords: "32:hex", PasswordsOutput: "passwords.yaml"},
+		{Users: []string{"alice"}, GeneratePasswords: "72:alnum", PasswordsOutput: "passwords.yaml"},
+	}
+	for _, cmd := range valid {
+		assert.NoError(t, cmd.Validate())
+	}
+	invalid := []SecretCreateBasicAuthCmd{
+		{},
+		{Users: []string{"alice", "alice"}},
+		{Users: []string{"al:ice"}},
+		{Users: []string{"alice smith"}},
+		{Users: []string{"alice"}, GeneratePasswords: "24"},
+		{Users: []string{"alice"}, GeneratePasswords: "24:words", PasswordsOutput: "passwords.yaml"},
+		{Users: []string{"alice@example.com"}, PasswordsOutput: "passwords.yaml"},
+		{Users: []string{"alice"}, GeneratePasswords: "73:alnum", PasswordsOutput: "passwords.yaml"},
+		{Users: []string{"alice"}, GeneratePasswords: "55:base64", PasswordsOutput: "passwords.yaml"},
+	}
+	for _, cmd := range invalid {
+		assert.Error(t, cmd.Validate(), cmd.Users)
+	}
+}
+
+func TestCreateSecretBasicAuth_HashesPromptedPasswords(t *testing.T) {
+	uut := &SecretCreateBasicAuthCmd{
+		createPipeline: createPipeline{Name: "dashboard-auth", Namespace: "ingress", IOStreams: genericiooptions.IOStreams{ErrOut: &bytes.Buffer{}}},
+		Users:          []string{"alice", "bob"},
+		readPassword:   fakePasswordReader("alice-pw", "alice-pw", "bob-pw", "bob-pw"),
+	}
+
+	secret, err := uut.createSecretBasicAuth()
+	require.NoError(t, err)
+	require.Len(t, secret.Data, 1)
+	lines := strings.Split(strings.TrimSuffix(string(secret.Data[htpasswdKey]), "\n"), "\n")
+	require.Len(t, lines, 2)
+	for i, user := range []string{"alice", "bob"} {
+		name, hash, found := strings.Cut(lines[i], ":")
+		require.True(t, found)
+		assert.Equal(t, user, name)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte(user+"-pw")))
+	}
+}
+
+func TestCreateSecretBasicAuth_RejectsTooLongPasswordBeforeTheNextPrompt(t *testing.T) {
+	long := strings.Repeat("x", 73)
+	uut := &SecretCreateBasicAuthCmd{
+		createPipeline: createPipeline{Name: "dashboard-auth", IOStreams: genericiooptions.IOStreams{ErrOut: &bytes.Buffer{}}},
+		Users:          []string{"alice", "bob"},
+		// bob is never prompted for, the reader has no entries left for him
+		readPassword: fakePasswordReader(long, long),
+	}
+
+	_, err := uut.createSecretBasicAuth()
+	assert.ErrorContains(t, err, "the password of alice is 73 bytes long")
+}
+
+func TestCreateSecretPasswords_KeepsGeneratedPasswords(t *testing.T) {
+	uut := &SecretCreateBasicAuthCmd{
+		createPipeline:    createPipeline{Name: "dashboard-auth", Namespace: "ingress"},
+		Users:             []string{"alice"},
+		GeneratePasswords: "16:hex",
+		PasswordsOutput:   "passwords.yaml",
+	}
+	require.NoError(t, uut.Validate())
+	uut.passwordsPipeline.Name = "dashboard-auth-passwords"
+
+	secret, err := uut.createSecretBasicAuth()
+	require.NoError(t, err)
+	passwords, err := uut.createSecretPasswords()
+	require.NoError(t, err)
+
+	assert.Equal(t, "dashboard-auth-passwords", passwords.Name)
+	assert.Equal(t, "ingress", passwords.Namespace)
+	password := passwords.Data["alice"]
+	assert.Len(t, password, 16)
+	hash := strings.TrimSpace(strings.TrimPrefix(string(secret.Data[htpasswdKey]), "alice:"))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), password))
+}
+
+func TestSecretCreateBasicAuthCmd_KeepsHtpasswdUnwrittenWhenPasswordsFail(t *testing.T) {
+	skm := testutil.NewKeyManager(t, "prod")
+
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "blocker")
+	require.NoError(t, os.WriteFile(blocker, nil, 0600))
+	uut := NewSecretCreateBasicAuthCmd(encryption.NewSopsAgeDecryptStrategy(), skm, testutil.NopAuditLog{})
+	uut.Name = "dashboard-auth"
+	uut.Cluster = "prod"
+	uut.Output = filepath.Join(dir, "auth.yaml")
+	uut.Users = []string{"alice"}
+	uut.GeneratePasswords = "16:hex"
+	// the passwords cannot be written below a regular file
+	uut.PasswordsOutput = filepath.Join(blocker, "passwords.yaml")
+	require.NoError(t, uut.Validate())
+	uut.passwordsPipeline = uut.createPipeline
+	uut.passwordsPipeline.Name = "dashboard-auth-passwords"
+	uut.passwordsPipeline.Output = uut.PasswordsOutput
+
+	_, err := uut.Execute()
+	require.Error(t, err)
+	assert.NoFileExists(t, uut.Output)
+}
@@ -0,0 +1,96 @@
+package create
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sopsctl/pkg/domain"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	kubectlcreate "k8s.io/kubectl/pkg/cmd/create"
+)
+
+const defaultDockerServer = "https://index.docker.io/v1/"
+
+// SecretCreateDockerRegistryCmd mirrors kubectl create secret docker-registry and
+// encrypts the result
+type SecretCreateDockerRegistryCmd struct {
+	createPipeline
+
+	Username    string
+	Password    string
+	Email       string
+	Server      string
+	FileSources []string
+}
+
+func NewSecretCreateDockerRegistryCmd(es domain.EncryptionService, skm domain.SopsKeyManager, auditLog domain.AuditLog) *SecretCreateDockerRegistryCmd {
+	return &SecretCreateDockerRegistryCmd{createPipeline: newCreatePipeline(es, skm, auditLog)}
+}
+
+func (s *SecretCreateDockerRegistryCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.Username, "docker-username", s.Username, "Username for Docker registry authentication")
+	cmd.Flags().StringVar(&s.Password, "docker-password", s.Password, "Password for Docker registry authentication")
+	cmd.Flags().StringVar(&s.Email, "docker-email", s.Email, "Email for Docker registry")
+	cmd.Flags().StringVar(&s.Server, "docker-server", defaultDockerServer, "Server location for Docker registry")
+	cmd.Flags().StringSliceVar(&s.FileSources, "from-file", s.FileSources, "Key files can be specified using their file path, in which case a default name of "+corev1.DockerConfigJsonKey+" will be given to them, or optionally with a name and file path, in which case the given name will be used.")
+	s.initCommonFlags(cmd)
+}
+
+func (s *SecretCreateDockerRegistryCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	if err := s.useCommonOptions(cmd, args); err != nil {
+		return nil, err
+	}
+	s.Username, _ = cmd.Flags().GetString("docker-username")
+	s.Password, _ = cmd.Flags().GetString("docker-password")
+	s.Email, _ = cmd.Flags().GetString("docker-email")
+	s.Server, _ = cmd.Flags().GetString("docker-server")
+	s.FileSources, _ = cmd.Flags().GetStringSlice("from-file")
+	for i := range s.FileSources {
+		if !strings.Contains(s.FileSources[i], "=") {
+			s.FileSources[i] = corev1.DockerConfigJsonKey + "=" + s.FileSources[i]
+		}
+	}
+	if len(s.FileSources) == 0 && (len(s.Username) == 0 || len(s.Password) == 0 || len(s.Server) == 0) {
+		return nil, fmt.Errorf("either --from-file or the combination of --docker-username, --docker-password and --docker-server is required")
+	}
+	return s, nil
+}
+
+func (s *SecretCreateDockerRegistryCmd) Execute() (string, error) {
+	return s.execute(domain.SecretCreateDockerRegistry, s.createSecretDockerRegistry)
+}
+
+// createSecretDockerRegistry is based on kubectl's
+// CreateSecretDockerRegistryOptions.createSecretDockerRegistry()
+func (s *SecretCreateDockerRegistryCmd) createSecretDockerRegistry() (*corev1.Secret, error) {
+	secret := newSecretObj(s.Name, s.Namespace, corev1.SecretTypeDockerConfigJson)
+	if len(s.FileSources) > 0 {
+		if err := handleSecretFromFileSources(secret, s.FileSources); err != nil {
+			return nil, err
+		}
+		return secret, nil
+	}
+	content, err := handleDockerCfgJSONContent(s.Username, s.Password, s.Email, s.Server)
+	if err != nil {
+		return nil, err
+	}
+	secret.Data[corev1.DockerConfigJsonKey] = content
+	return secret, nil
+}
+
+// handleDockerCfgJSONContent serializes a ~/.docker/config.json file
+func handleDockerCfgJSONContent(username, password, email, server string) ([]byte, error) {
+	dockerConfigAuth := kubectlcreate.DockerConfigEntry{
+		Username: username,
+		Password: password,
+		Email:    email,
+		Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+	}
+	dockerConfigJSON := kubectlcreate.DockerConfigJSON{
+		Auths: map[string]kubectlcreate.DockerConfigEntry{server: dockerConfigAuth},
+	}
+	return json.Marshal(dockerConfigJSON)
+}
@@ -0,0 +1,232 @@
+package create
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/generate"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Keys of the Flux source authentication secrets
+const (
+	fluxIdentityKey    = "identity"
+	fluxIdentityPubKey = "identity.pub"
+	fluxKnownHostsKey  = "known_hosts"
+	fluxUsernameKey    = "username"
+	fluxPasswordKey    = "password"
+	fluxBearerTokenKey = "bearerToken"
+	fluxCAKey          = "ca.crt"
+)
+
+// SecretCreateFluxAuthCmd creates the authentication secrets Flux GitRepository,
+// HelmRepository and OCIRepository sources reference with secretRef.
+type SecretCreateFluxAuthCmd struct {
+	createPipeline
+
+	GitSSH         bool
+	PrivateKeyFile string
+	KnownHostsFrom string
+	KnownHostsFile string
+	BasicAuth      string
+	BearerToken    string
+	CAFile         string
+	// generatedPublicKey is the public key of a deploy key generated for --git-ssh
+	generatedPublicKey []byte
+}
+
+func NewSecretCreateFluxAuthCmd(es domain.EncryptionService, skm domain.SopsKeyManager, auditLog domain.AuditLog) *SecretCreateFluxAuthCmd {
+	return &SecretCreateFluxAuthCmd{createPipeline: newCreatePipeline(es, skm, auditLog)}
+}
+
+func (s *SecretCreateFluxAuthCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&s.GitSSH, "git-ssh", s.GitSSH, "Create an SSH secret for a GitRepository with identity, identity.pub and known_hosts")
+	cmd.Flags().StringVar(&s.PrivateKeyFile, "private-key-file", s.PrivateKeyFile, "Path to the SSH private key, a new ed25519 deploy key is generated when omitted")
+	cmd.Flags().StringVar(&s.KnownHostsFrom, "known-hosts-from", s.KnownHostsFrom, "Read known_hosts from the host keys of this SSH server (host or host:port)")
+	cmd.Flags().StringVar(&s.KnownHostsFile, "known-hosts-file", s.KnownHostsFile, "Path to a known_hosts file")
+	cmd.Flags().StringVar(&s.BasicAuth, "basic-auth", s.BasicAuth, "Create a basic auth secret from user:password, the password is prompted for when only the user is given")
+	cmd.Flags().StringVar(&s.BearerToken, "bearer-token", s.BearerToken, "Create a bearer token secret")
+	cmd.Flags().StringVar(&s.CAFile, "ca-file", s.CAFile, "Path to a PEM encoded CA certificate stored under ca.crt")
+	s.initCommonFlags(cmd)
+}
+
+func (s *SecretCreateFluxAuthCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	if err := s.useCommonOptions(cmd, args); err != nil {
+		return nil, err
+	}
+	s.GitSSH, _ = cmd.Flags().GetBool("git-ssh")
+	s.PrivateKeyFile, _ = cmd.Flags().GetString("private-key-file")
+	s.KnownHostsFrom, _ = cmd.Flags().GetString("known-hosts-from")
+	s.KnownHostsFile, _ = cmd.Flags().GetString("known-hosts-file")
+	s.BasicAuth, _ = cmd.Flags().GetString("basic-auth")
+	s.BearerToken, _ = cmd.Flags().GetString("bearer-token")
+	s.CAFile, _ = cmd.Flags().GetString("ca-file")
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate checks that the flags describe exactly one kind of Flux authentication.
+func (s *SecretCreateFluxAuthCmd) Validate() error {
+	modes := 0
+	for _, set := range []bool{s.GitSSH, s.BasicAuth != "", s.BearerToken != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return fmt.Errorf("--git-ssh, --basic-auth and --bearer-token cannot be combined")
+	}
+	if modes == 0 && s.CAFile == "" {
+		return fmt.Errorf("one of --git-ssh, --basic-auth, --bearer-token or --ca-file is required")
+	}
+	sshFlagSet := s.PrivateKeyFile != "" || s.KnownHostsFrom != "" || s.KnownHostsFile != ""
+	if !s.GitSSH && sshFlagSet {
+		return fmt.Errorf("--private-key-file, --known-hosts-from and --known-hosts-file require --git-ssh")
+	}
+	if s.GitSSH {
+		if (s.KnownHostsFrom == "") == (s.KnownHostsFile == "") {
+			return fmt.Errorf("--git-ssh requires exactly one of --known-hosts-from or --known-hosts-file, Flux verifies the host key")
+		}
+	}
+	if s.BasicAuth != "" && strings.HasPrefix(s.BasicAuth, ":") {
+		return fmt.Errorf("--basic-auth needs a user name")
+	}
+	if s.BearerToken != "" && strings.ContainsAny(s.BearerToken, " \t\r\n") {
+		return fmt.Errorf("--bearer-token must not contain whitespace")
+	}
+	return nil
+}
+
+func (s *SecretCreateFluxAuthCmd) Execute() (string, error) {
+	result, err := s.execute(domain.SecretCreateFluxAuth, s.createSecretFluxAuth)
+	if err == nil && !s.DryRun && len(s.generatedPublicKey) > 0 {
+		_, _ = fmt.Fprintf(s.IOStreams.ErrOut, "Generated a new deploy key, add this public key to the repository:\n%s", s.generatedPublicKey)
+	}
+	return result, err
+}
+
+func (s *SecretCreateFluxAuthCmd) createSecretFluxAuth() (*corev1.Secret, error) {
+	secret := newSecretObj(s.Name, s.Namespace, corev1.SecretTypeOpaque)
+	if s.GitSSH {
+		if err := s.addGitSSH(secret); err != nil {
+			return nil, err
+		}
+	}
+	if s.BasicAuth != "" {
+		if err := s.addBasicAuth(secret); err != nil {
+			return nil, err
+		}
+	}
+	if s.BearerToken != "" {
+		secret.Data[fluxBearerTokenKey] = []byte(s.BearerToken)
+	}
+	if s.CAFile != "" {
+		ca, err := os.ReadFile(s.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA certificate %s: %w", s.CAFile, err)
+		}
+		if err := validateCertificates(ca); err != nil {
+			return nil, fmt.Errorf("invalid CA certificate %s: %w", s.CAFile, err)
+		}
+		secret.Data[fluxCAKey] = ca
+	}
+	return secret, nil
+}
+
+func (s *SecretCreateFluxAuthCmd) addGitSSH(secret *corev1.Secret) error {
+	var knownHosts []byte
+	var err error
+	if s.KnownHostsFrom != "" {
+		knownHosts, err = scanKnownHosts(s.KnownHostsFrom)
+	} else {
+		knownHosts, err = os.ReadFile(s.KnownHostsFile)
+	}
+	if err != nil {
+		return err
+	}
+	if err := validateKnownHosts(knownHosts); err != nil {
+		return err
+	}
+
+	var identity, identityPub []byte
+	if s.PrivateKeyFile != "" {
+		identity, err = os.ReadFile(s.PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("cannot read private key %s: %w", s.PrivateKeyFile, err)
+		}
+		signer, err := ssh.ParsePrivateKey(identity)
+		var passphraseErr *ssh.PassphraseMissingError
+		if errors.As(err, &passphraseErr) {
+			return fmt.Errorf("private key %s is protected by a passphrase, Flux needs a key without one", s.PrivateKeyFile)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid private key %s: %w", s.PrivateKeyFile, err)
+		}
+		identityPub = ssh.MarshalAuthorizedKey(signer.PublicKey())
+	} else {
+		identity, identityPub, err = generate.SSHKeyPair(generate.Ed25519KeyType, s.Name)
+		if err != nil {
+			return err
+		}
+		s.generatedPublicKey = identityPub
+	}
+
+	secret.Data[fluxIdentityKey] = identity
+	secret.Data[fluxIdentityPubKey] = identityPub
+	secret.Data[fluxKnownHostsKey] = knownHosts
+	return nil
+}
+
+func (s *SecretCreateFluxAuthCmd) addBasicAuth(secret *corev1.Secret) error {
+	username, password, hasPassword := strings.Cut(s.BasicAuth, ":")
+	if !hasPassword {
+		read, err := terminalPasswordReader(s.IOStreams.In)
+		if err != nil {
+			return fmt.Errorf("--basic-auth without a password prompts for it: %w", err)
+		}
+		value, err := promptValue(fluxPasswordKey, read, s.IOStreams.ErrOut)
+		if err != nil {
+			return err
+		}
+		password = string(value)
+	}
+	if password == "" {
+		return fmt.Errorf("--basic-auth needs a password")
+	}
+	secret.Data[fluxUsernameKey] = []byte(username)
+	secret.Data[fluxPasswordKey] = []byte(password)
+	return nil
+}
+
+// validateCertificates checks that content holds at least one PEM encoded certificate
+// and nothing else.
+func validateCertificates(content []byte) error {
+	certificates := 0
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("unexpected PEM block %s", block.Type)
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return err
+		}
+		certificates++
+	}
+	if certificates == 0 {
+		return fmt.Errorf("no PEM encoded certificate found")
+	}
+	return nil
+}
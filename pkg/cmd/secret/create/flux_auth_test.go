@@ -0,0 +1,131 @@
+package create
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/services/generate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const testKnownHosts = "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl\n"
+
+func TestSecretCreateFluxAuthCmd_Validate(t *testing.T) {
+	valid := []SecretCreateFluxAuthCmd{
+		{GitSSH: true, KnownHostsFrom: "github.com"},
+		{BasicAuth: "robot:pass"},
+		{BearerToken: "token", CAFile: "ca.crt"},
+		{CAFile: "ca.crt"},
+	}
+	for _, cmd := range valid {
+		assert.NoError(t, cmd.Validate())
+	}
+	invalid := []SecretCreateFluxAuthCmd{
+		{},
+		{GitSSH: true},
+		{GitSSH: true, KnownHostsFrom: "github.com", KnownHostsFile: "known_hosts"},
+		{BasicAuth: "robot:pass", BearerToken: "token"},
+		{BasicAuth: "robot:pass", PrivateKeyFile: "identity"},
+		{BasicAuth: ":pass"},
+		{BearerToken: "two words"},
+	}
+	for _, cmd := range invalid {
+		assert.Error(t, cmd.Validate())
+	}
+}
+
+func TestCreateSecretFluxAuth_GitSSHFromPrivateKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	identity, identityPub, err := generate.SSHKeyPair(generate.Ed25519KeyType, "")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "identity"), identity, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "known_hosts"), []byte(testKnownHosts), 0600))
+	uut := &SecretCreateFluxAuthCmd{
+		createPipeline: createPipeline{Name: "flux-system", Namespace: "flux-system"},
+		GitSSH:         true,
+		PrivateKeyFile: filepath.Join(dir, "identity"),
+		KnownHostsFile: filepath.Join(dir, "known_hosts"),
+	}
+
+	secret, err := uut.createSecretFluxAuth()
+	require.NoError(t, err)
+	assert.Equal(t, identity, secret.Data[fluxIdentityKey])
+	assert.Equal(t, identityPub, secret.Data[fluxIdentityPubKey])
+	assert.Equal(t, testKnownHosts, string(secret.Data[fluxKnownHostsKey]))
+	assert.Empty(t, uut.generatedPublicKey)
+}
+
+func TestCreateSecretFluxAuth_RejectsInvalidContent(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "identity"), []byte("not a key"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "known_hosts"), []byte(testKnownHosts), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), []byte("not a certificate"), 0600))
+
+	uut := &SecretCreateFluxAuthCmd{GitSSH: true, PrivateKeyFile: filepath.Join(dir, "identity"), KnownHostsFile: filepath.Join(dir, "known_hosts")}
+	_, err := uut.createSecretFluxAuth()
+	assert.ErrorContains(t, err, "invalid private key")
+
+	uut = &SecretCreateFluxAuthCmd{CAFile: filepath.Join(dir, "ca.crt")}
+	_, err = uut.createSecretFluxAuth()
+	assert.ErrorContains(t, err, "invalid CA certificate")
+}
+
+func TestCreateSecretFluxAuth_BasicAuthAndToken(t *testing.T) {
+	uut := &SecretCreateFluxAuthCmd{createPipeline: createPipeline{Name: "charts"}, BasicAuth: "robot:pa:ss"}
+	secret, err := uut.createSecretFluxAuth()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{fluxUsernameKey: []byte("robot"), fluxPasswordKey: []byte("pa:ss")}, secret.Data)
+	assert.Equal(t, corev1.SecretTypeOpaque, secret.Type)
+
+	uut = &SecretCreateFluxAuthCmd{createPipeline: createPipeline{Name: "registry"}, BearerToken: "token"}
+	secret, err = uut.createSecretFluxAuth()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{fluxBearerTokenKey: []byte("token")}, secret.Data)
+}
+
+func TestValidateKnownHosts(t *testing.T) {
+	assert.NoError(t, validateKnownHosts([]byte("# comment\n"+testKnownHosts)))
+	assert.Error(t, validateKnownHosts([]byte("# only a comment\n")))
+	assert.Error(t, validateKnownHosts([]byte("github.com not-a-key\n")))
+}
+
+func TestScanKnownHosts(t *testing.T) {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+	go func() {
+		config := &ssh.ServerConfig{NoClientAuth: true}
+		config.AddHostKey(signer)
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _, _, _ = ssh.NewServerConn(conn, config)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	knownHosts, err := scanKnownHosts(listener.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, validateKnownHosts(knownHosts))
+	_, hosts, key, _, _, err := ssh.ParseKnownHosts(knownHosts)
+	require.NoError(t, err)
+	assert.Equal(t, signer.PublicKey().Marshal(), key.Marshal())
+	assert.Len(t, hosts, 1)
+}
@@ -0,0 +1,177 @@
+package create
+
+import (
+	"fmt"
+	"os"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/generate"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+const defaultWireGuardKey = "wireguard"
+
+// parseSSHKeyPairSource parses a key[=type] source. The public key is stored under key.pub.
+func parseSSHKeyPairSource(source string) (string, string, error) {
+	keyName, keyType, _ := strings.Cut(source, "=")
+	keyType, err := generate.ParseSSHKeyType(keyType)
+	if err != nil {
+		return "", "", err
+	}
+	if err := validateKeyName(keyName); err != nil {
+		return "", "", err
+	}
+	return keyName, keyType, nil
+}
+
+func validateKeyName(keyName string) error {
+	if errs := validation.IsConfigMapKey(keyName); len(errs) != 0 {
+		return fmt.Errorf("%q is not valid key name for a Secret %s", keyName, strings.Join(errs, ";"))
+	}
+	return nil
+}
+
+// validateGenerators checks the generator flags before anything is generated.
+func (s *SecretCreateCmd) validateGenerators() error {
+	for _, source := range s.SSHKeyPairSources {
+		if _, _, err := parseSSHKeyPairSource(source); err != nil {
+			return err
+		}
+	}
+	if s.TLSSource != "" {
+		spec, err := generate.ParseCertificateSpec(s.TLSSource)
+		if err != nil {
+			return fmt.Errorf("invalid --generate-tls: %w", err)
+		}
+		if spec.CARef != "" {
+			if _, err := os.Stat(spec.CARef); err != nil {
+				return fmt.Errorf("CA secret %s does not exist", spec.CARef)
+			}
+		}
+	}
+	if s.WireGuardSource != "" {
+		return validateKeyName(s.WireGuardSource)
+	}
+	return nil
+}
+
+// handleSecretFromGenerators creates the key material in memory and adds it to the secret.
+func (s *SecretCreateCmd) handleSecretFromGenerators(secret *corev1.Secret) error {
+	for _, source := range s.SSHKeyPairSources {
+		keyName, keyType, err := parseSSHKeyPairSource(source)
+		if err != nil {
+			return err
+		}
+		privateKey, publicKey, err := generate.SSHKeyPair(keyType, s.Name)
+		if err != nil {
+			return err
+		}
+		if err := addKeyPairToSecret(secret, keyName, privateKey, publicKey); err != nil {
+			return err
+		}
+	}
+	if s.TLSSource != "" {
+		if err := s.addGeneratedCertificate(secret); err != nil {
+			return err
+		}
+	}
+	if s.WireGuardSource != "" {
+		privateKey, publicKey, err := generate.WireGuardKeyPair()
+		if err != nil {
+			return err
+		}
+		if err := addKeyPairToSecret(secret, s.WireGuardSource, privateKey, publicKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SecretCreateCmd) addGeneratedCertificate(secret *corev1.Secret) error {
+	spec, err := generate.ParseCertificateSpec(s.TLSSource)
+	if err != nil {
+		return fmt.Errorf("invalid --generate-tls: %w", err)
+	}
+	var caCert, caKey []byte
+	if spec.CARef != "" {
+		if caCert, caKey, err = s.loadCA(spec.CARef); err != nil {
+			return err
+		}
+	}
+	cert, key, err := generate.Certificate(spec, caCert, caKey)
+	if err != nil {
+		return err
+	}
+	if err := addKeyFromLiteralToSecret(secret, corev1.TLSCertKey, cert); err != nil {
+		return err
+	}
+	if err := addKeyFromLiteralToSecret(secret, corev1.TLSPrivateKeyKey, key); err != nil {
+		return err
+	}
+	if len(caCert) > 0 {
+		if err := addKeyFromLiteralToSecret(secret, corev1.ServiceAccountRootCAKey, caCert); err != nil {
+			return err
+		}
+	}
+	if secret.Type == "" {
+		secret.Type = corev1.SecretTypeTLS
+	}
+	return nil
+}
+
+// loadCA decrypts the CA certificate and key from an encrypted secret with tls.crt and
+// tls.key, such as one created with --generate-tls is-ca. A CA key is only ever read from
+// an encrypted file, so it never has to exist on disk in plain text.
+func (s *SecretCreateCmd) loadCA(caRef string) ([]byte, []byte, error) {
+	recipients, err := s.encryptionService.Recipients(caRef)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(recipients) == 0 {
+		return nil, nil, fmt.Errorf("CA secret %s is not encrypted with age, keep the CA key in an encrypted secret", caRef)
+	}
+	ctxName, err := s.sopsKeyManager.FindCtxForRecipients(recipients, s.Cluster)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decrypt CA secret %s: %w", caRef, err)
+	}
+	caKeys := []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey}
+	cert, key, err := s.decryptCA(caRef, ctxName)
+	s.auditLog.Record(domain.NewAuditEntry(domain.SecretDecrypt, ctxName, caRef, caKeys, err))
+	return cert, key, err
+}
+
+func (s *SecretCreateCmd) decryptCA(caRef string, ctxName string) ([]byte, []byte, error) {
+	privateKey, err := s.sopsKeyManager.GetPrivateKey(ctxName)
+	if err != nil {
+		return nil, nil, err
+	}
+	decrypted, err := s.encryptionService.Decrypt(caRef, privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt CA secret %s: %w", caRef, err)
+	}
+	var caSecret corev1.Secret
+	if err := yaml.Unmarshal(decrypted, &caSecret); err != nil {
+		return nil, nil, fmt.Errorf("CA secret %s is not a Secret manifest: %w", caRef, err)
+	}
+	value := func(key string) []byte {
+		if v, ok := caSecret.StringData[key]; ok {
+			return []byte(v)
+		}
+		return caSecret.Data[key]
+	}
+	cert, key := value(corev1.TLSCertKey), value(corev1.TLSPrivateKeyKey)
+	if len(cert) == 0 || len(key) == 0 {
+		return nil, nil, fmt.Errorf("CA secret %s has no %s and %s", caRef, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+	return cert, key, nil
+}
+
+func addKeyPairToSecret(secret *corev1.Secret, keyName string, privateKey []byte, publicKey []byte) error {
+	if err := addKeyFromLiteralToSecret(secret, keyName, privateKey); err != nil {
+		return err
+	}
+	return addKeyFromLiteralToSecret(secret, keyName+".pub", publicKey)
+}
@@ -0,0 +1,122 @@
+package create
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultValuesKey is the key Flux reads values from when valuesKey is not set
+const defaultValuesKey = "values.yaml"
+
+// SecretCreateHelmValuesCmd creates a secret with chart values for the valuesFrom list
+// of a Flux HelmRelease.
+type SecretCreateHelmValuesCmd struct {
+	createPipeline
+
+	ValuesFile string
+	ValuesKey  string
+
+	// secret is the secret built by createSecretHelmValues, its name includes the hash
+	// once --append-hash is applied
+	secret *corev1.Secret
+}
+
+func NewSecretCreateHelmValuesCmd(es domain.EncryptionService, skm domain.SopsKeyManager, auditLog domain.AuditLog) *SecretCreateHelmValuesCmd {
+	return &SecretCreateHelmValuesCmd{
+		createPipeline: newCreatePipeline(es, skm, auditLog),
+		ValuesKey:      defaultValuesKey,
+	}
+}
+
+func (s *SecretCreateHelmValuesCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.ValuesFile, "from-values", s.ValuesFile, "Path to the Helm values file to embed")
+	cmd.Flags().StringVar(&s.ValuesKey, "values-key", s.ValuesKey, "Key the values are stored under")
+	_ = cmd.MarkFlagRequired("from-values")
+	s.initCommonFlags(cmd)
+}
+
+func (s *SecretCreateHelmValuesCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	if err := s.useCommonOptions(cmd, args); err != nil {
+		return nil, err
+	}
+	s.ValuesFile, _ = cmd.Flags().GetString("from-values")
+	s.ValuesKey, _ = cmd.Flags().GetString("values-key")
+	if err := validateKeyName(s.ValuesKey); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SecretCreateHelmValuesCmd) Execute() (string, error) {
+	result, err := s.execute(domain.SecretCreateHelmValues, s.createSecretHelmValues)
+	if err == nil {
+		// stdout may hold the encrypted secret, so the snippet goes to stderr. The
+		// HelmRelease references the secret by the name kustomize builds it as, since
+		// kustomize does not rewrite valuesFrom.
+		_, _ = fmt.Fprint(s.IOStreams.ErrOut, valuesFromSnippet(s.builtName(s.secret.Name), s.ValuesKey))
+	}
+	return result, err
+}
+
+func (s *SecretCreateHelmValuesCmd) createSecretHelmValues() (*corev1.Secret, error) {
+	values, err := os.ReadFile(s.ValuesFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read values file %s: %w", s.ValuesFile, err)
+	}
+	if err := validateHelmValues(values); err != nil {
+		return nil, fmt.Errorf("invalid values file %s: %w", s.ValuesFile, err)
+	}
+	s.secret = newSecretObj(s.Name, s.Namespace, corev1.SecretTypeOpaque)
+	s.secret.Data[s.ValuesKey] = values
+	return s.secret, nil
+}
+
+// validateHelmValues checks that content is a single YAML document holding a map, as
+// Helm expects of values, and that it is not a file sops has already encrypted.
+func validateHelmValues(content []byte) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	var doc yaml.Node
+	if err := decoder.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("no values found")
+		}
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return fmt.Errorf("no values found")
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("values must be a map at the top level")
+	}
+	// Decoding into a map also catches keys that are given twice
+	var values map[string]interface{}
+	if err := doc.Decode(&values); err != nil {
+		return err
+	}
+	if _, encrypted := values["sops"]; encrypted {
+		return fmt.Errorf("the file is encrypted with sops, decrypt it first")
+	}
+	if err := decoder.Decode(&yaml.Node{}); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("values must be a single YAML document")
+	}
+	return nil
+}
+
+// valuesFromSnippet returns the valuesFrom entry a HelmRelease uses to read the values
+// from the secret.
+func valuesFromSnippet(name string, valuesKey string) string {
+	return fmt.Sprintf(`Reference the values from the HelmRelease in the same namespace:
+  valuesFrom:
+    - kind: Secret
+      name: %s
+      valuesKey: %s
+`, name, valuesKey)
+}
@@ -0,0 +1,68 @@
+package create
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/services/encryption"
+	"sopsctl/pkg/services/testutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestValidateHelmValues(t *testing.T) {
+	assert.NoError(t, validateHelmValues([]byte("# credentials\ndatabase:\n  password: hunter2\n")))
+
+	invalid := map[string]string{
+		"empty":          "# only a comment\n",
+		"list":           "- a\n- b\n",
+		"scalar":         "hunter2\n",
+		"duplicate key":  "a: 1\na: 2\n",
+		"two documents":  "a: 1\n---\nb: 2\n",
+		"sops encrypted": "a: ENC[AES256_GCM,data:abc,type:str]\nsops:\n  version: 3.9.0\n",
+		"malformed":      "a: [1\n",
+	}
+	for name, content := range invalid {
+		assert.Error(t, validateHelmValues([]byte(content)), name)
+	}
+}
+
+func TestCreateSecretHelmValues(t *testing.T) {
+	values := "# credentials\ndatabase:\n  password: hunter2\n"
+	path := filepath.Join(t.TempDir(), "values.secret.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(values), 0600))
+	uut := &SecretCreateHelmValuesCmd{
+		createPipeline: createPipeline{Name: "podinfo-values", Namespace: "podinfo"},
+		ValuesFile:     path,
+		ValuesKey:      "secret-values.yaml",
+	}
+
+	secret, err := uut.createSecretHelmValues()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"secret-values.yaml": []byte(values)}, secret.Data)
+	assert.Contains(t, valuesFromSnippet(secret.Name, uut.ValuesKey), "      name: podinfo-values\n      valuesKey: secret-values.yaml\n")
+}
+
+func TestSecretCreateHelmValuesCmd_SnippetUsesTheNameKustomizeBuilds(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("namePrefix: prod-\nnameSuffix: -v1\n"), 0644))
+	path := filepath.Join(dir, "values.secret.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("database:\n  password: hunter2\n"), 0600))
+	errOut := &bytes.Buffer{}
+	uut := NewSecretCreateHelmValuesCmd(encryption.NewSopsAgeDecryptStrategy(), testutil.NewKeyManager(t, "prod"), testutil.NopAuditLog{})
+	uut.Name = "podinfo-values"
+	uut.Cluster = "prod"
+	uut.DryRun = true
+	uut.Output = filepath.Join(dir, "podinfo-values.enc.yaml")
+	uut.IOStreams = genericiooptions.IOStreams{ErrOut: errOut}
+	uut.ValuesFile = path
+	_, err := uut.applyKustomizationDefaults(false)
+	require.NoError(t, err)
+
+	_, err = uut.Execute()
+	require.NoError(t, err)
+	assert.Contains(t, errOut.String(), "      name: prod-podinfo-values-v1\n")
+}
@@ -0,0 +1,61 @@
+package create
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// passwordReader reads one value from the terminal without echoing it.
+type passwordReader func() ([]byte, error)
+
+// terminalPasswordReader returns a passwordReader for in, which must be a terminal.
+func terminalPasswordReader(in io.Reader) (passwordReader, error) {
+	f, ok := in.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return nil, fmt.Errorf("stdin is not an interactive terminal")
+	}
+	return func() ([]byte, error) {
+		return term.ReadPassword(int(f.Fd()))
+	}, nil
+}
+
+// promptValue asks for the value of key twice and returns it when both entries match.
+// The prompts are written to out, which should not be stdout so the encrypted secret
+// can still be redirected.
+func promptValue(key string, read passwordReader, out io.Writer) ([]byte, error) {
+	_, _ = fmt.Fprintf(out, "Value for %s: ", key)
+	value, err := read()
+	_, _ = fmt.Fprintln(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read value for %s: %w", key, err)
+	}
+	if len(value) == 0 {
+		return nil, fmt.Errorf("no value entered for %s", key)
+	}
+	_, _ = fmt.Fprintf(out, "Confirm value for %s: ", key)
+	confirmation, err := read()
+	_, _ = fmt.Fprintln(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read value for %s: %w", key, err)
+	}
+	if !bytes.Equal(value, confirmation) {
+		return nil, fmt.Errorf("the values entered for %s do not match", key)
+	}
+	return value, nil
+}
+
+// readStdinValue reads a single value from in. One trailing newline is dropped, so
+// echo and here-strings give the value as typed.
+func readStdinValue(in io.Reader) ([]byte, error) {
+	value, err := io.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+	value = bytes.TrimSuffix(value, []byte("\n"))
+	value = bytes.TrimSuffix(value, []byte("\r"))
+	return value, nil
+}
@@ -0,0 +1,46 @@
+package create
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakePasswordReader(entries ...string) passwordReader {
+	return func() ([]byte, error) {
+		entry := entries[0]
+		entries = entries[1:]
+		return []byte(entry), nil
+	}
+}
+
+func TestPromptValue(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	value, err := promptValue("password", fakePasswordReader("hunter2", "hunter2"), out)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(value))
+	assert.Contains(t, out.String(), "Confirm value for password")
+	assert.NotContains(t, out.String(), "hunter2")
+}
+
+func TestPromptValue_RejectsMismatchAndEmpty(t *testing.T) {
+	_, err := promptValue("password", fakePasswordReader("hunter2", "hunter3"), &bytes.Buffer{})
+	assert.ErrorContains(t, err, "do not match")
+
+	_, err = promptValue("password", fakePasswordReader(""), &bytes.Buffer{})
+	assert.ErrorContains(t, err, "no value entered")
+}
+
+func TestReadStdinValue_DropsOneTrailingNewline(t *testing.T) {
+	value, err := readStdinValue(strings.NewReader("s3cret\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(value))
+
+	value, err = readStdinValue(strings.NewReader("line1\nline2\n\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "line1\nline2\n", string(value))
+}
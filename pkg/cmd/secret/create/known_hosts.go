@@ -0,0 +1,76 @@
+package create
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const knownHostsScanTimeout = 10 * time.Second
+
+// knownHostsKeyAlgorithms are the host key types collected for known_hosts, like ssh-keyscan.
+var knownHostsKeyAlgorithms = []string{
+	ssh.KeyAlgoED25519,
+	ssh.KeyAlgoECDSA256,
+	ssh.KeyAlgoRSASHA512,
+}
+
+var errHostKeyCollected = errors.New("host key collected")
+
+// scanKnownHosts connects to the SSH server once per host key type and returns its host
+// keys as known_hosts lines. No authentication is attempted.
+func scanKnownHosts(host string) ([]byte, error) {
+	address := host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		address = net.JoinHostPort(host, "22")
+	}
+	var lines []string
+	var lastErr error
+	for _, algorithm := range knownHostsKeyAlgorithms {
+		var hostKey ssh.PublicKey
+		config := &ssh.ClientConfig{
+			User:              "git",
+			HostKeyAlgorithms: []string{algorithm},
+			Timeout:           knownHostsScanTimeout,
+			HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+				hostKey = key
+				return errHostKeyCollected
+			},
+		}
+		_, err := ssh.Dial("tcp", address, config)
+		if hostKey == nil {
+			lastErr = err
+			continue
+		}
+		lines = append(lines, knownhosts.Line([]string{knownhosts.Normalize(address)}, hostKey))
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("failed to read the host keys of %s: %w", address, lastErr)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// validateKnownHosts checks that every non-comment line of a known_hosts file parses.
+func validateKnownHosts(content []byte) error {
+	entries := 0
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		if _, _, _, _, _, err := ssh.ParseKnownHosts(trimmed); err != nil {
+			return fmt.Errorf("known_hosts line %d is invalid: %w", i+1, err)
+		}
+		entries++
+	}
+	if entries == 0 {
+		return fmt.Errorf("known_hosts has no entries")
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package create
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/services/file"
+	"sopsctl/pkg/services/yamlnode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kustomizationFileNames are the file names kustomize recognizes, in its order of precedence.
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// findKustomization returns the path of the kustomization file in dir.
+func findKustomization(dir string) (string, error) {
+	for _, name := range kustomizationFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no kustomization.yaml found in %s", dir)
+}
+
+// addKustomizationResource appends resource to the resources list of the kustomization
+// file, keeping its comments and the order of its fields. It reports false when the
+// resource was already listed.
+func addKustomizationResource(kustomizationPath string, resource string) (bool, error) {
+	info, err := os.Stat(kustomizationPath)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(kustomizationPath)
+	if err != nil {
+		return false, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", kustomizationPath, err)
+	}
+	if doc.Kind == 0 {
+		// an empty file decodes to no document at all
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return false, fmt.Errorf("%s is not a kustomization", kustomizationPath)
+	}
+
+	resources := yamlnode.MappingValue(root, "resources")
+	if resources == nil {
+		resources = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "resources"}, resources)
+	}
+	if resources.Kind != yaml.SequenceNode {
+		return false, fmt.Errorf("resources in %s is not a list", kustomizationPath)
+	}
+	for _, item := range resources.Content {
+		if item.Value == resource {
+			return false, nil
+		}
+	}
+	resources.Style = 0
+	resources.Content = append(resources.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: resource})
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return false, err
+	}
+	if err := encoder.Close(); err != nil {
+		return false, err
+	}
+	if err := file.AtomicWriteFile(kustomizationPath, buf.Bytes()); err != nil {
+		return false, err
+	}
+	// AtomicWriteFile restricts the file to its owner, the kustomization keeps its mode
+	return true, os.Chmod(kustomizationPath, info.Mode().Perm())
+}
+
+// kustomizationDefaults are the fields of a kustomization that kustomize applies to
+// every resource it builds.
+type kustomizationDefaults struct {
+	Namespace  string `yaml:"namespace"`
+	NamePrefix string `yaml:"namePrefix"`
+	NameSuffix string `yaml:"nameSuffix"`
+}
+
+// readKustomizationDefaults reads the namespace and name prefix and suffix of the
+// kustomization file.
+func readKustomizationDefaults(kustomizationPath string) (kustomizationDefaults, error) {
+	var defaults kustomizationDefaults
+	data, err := os.ReadFile(kustomizationPath)
+	if err != nil {
+		return defaults, err
+	}
+	if err := yaml.Unmarshal(data, &defaults); err != nil {
+		return defaults, fmt.Errorf("failed to parse %s: %w", kustomizationPath, err)
+	}
+	return defaults, nil
+}
@@ -0,0 +1,111 @@
+package create
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddKustomizationResource_KeepsCommentsAndSkipsDuplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kustomization.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("# apps\nnamespace: apps\nresources:\n  - deployment.yaml # the app\n"), 0644))
+
+	added, err := addKustomizationResource(path, "db.enc.yaml")
+	require.NoError(t, err)
+	assert.True(t, added)
+	added, err = addKustomizationResource(path, "db.enc.yaml")
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# apps\nnamespace: apps\nresources:\n  - deployment.yaml # the app\n  - db.enc.yaml\n", string(content))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestAddKustomizationResource_AddsMissingResources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kustomization.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("namespace: apps\n"), 0644))
+
+	_, err := addKustomizationResource(path, "db.enc.yaml")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "namespace: apps\nresources:\n  - db.enc.yaml\n", string(content))
+}
+
+func TestCheckOutput_RefusesOverwriteWithoutForce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.enc.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("existing"), 0600))
+
+	p := &createPipeline{Output: path}
+	assert.ErrorContains(t, p.checkOutput(), "already exists")
+
+	p = &createPipeline{Output: path, Force: true}
+	assert.NoError(t, p.checkOutput())
+
+	p = &createPipeline{Output: path, DryRun: true}
+	assert.NoError(t, p.checkOutput())
+}
+
+func TestWriteOutput_RefusesFileCreatedAfterTheCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.enc.yaml")
+	p := &createPipeline{Output: path}
+	require.NoError(t, p.checkOutput())
+	require.NoError(t, os.WriteFile(path, []byte("existing"), 0600))
+
+	_, err := p.writeOutput([]byte("encrypted"))
+	assert.ErrorContains(t, err, "already exists")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "existing", string(content))
+}
+
+func TestCheckOutput_RequiresKustomization(t *testing.T) {
+	p := &createPipeline{Output: filepath.Join(t.TempDir(), "secret.enc.yaml"), AddToKustomization: true}
+	assert.ErrorContains(t, p.checkOutput(), "no kustomization.yaml found")
+}
+
+func TestApplyKustomizationDefaults_TakesNamespaceFromOutputDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("namespace: apps\nnamePrefix: prod-\n"), 0644))
+
+	p := &createPipeline{Name: "db", Namespace: "default", Output: filepath.Join(dir, "db.enc.yaml")}
+	warnings, err := p.applyKustomizationDefaults(false)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "apps", p.Namespace)
+	assert.Equal(t, "prod-db", p.builtName("db"))
+
+	p = &createPipeline{Name: "db", Namespace: "default"}
+	warnings, err = p.applyKustomizationDefaults(false)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "default", p.Namespace)
+	assert.Equal(t, "db", p.builtName("db"))
+}
+
+func TestApplyKustomizationDefaults_WarnsAboutConflicts(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("namespace: apps\nnamePrefix: prod-\nnameSuffix: -v1\n"), 0644))
+
+	p := &createPipeline{Name: "prod-db-v1", Namespace: "other", KustomizationDir: dir}
+	warnings, err := p.applyKustomizationDefaults(true)
+	require.NoError(t, err)
+	assert.Equal(t, "other", p.Namespace)
+	require.Len(t, warnings, 3)
+	assert.Contains(t, warnings[0], "replaces --namespace other")
+	assert.Contains(t, warnings[1], "built as prod-prod-db-v1")
+	assert.Contains(t, warnings[2], "built as prod-db-v1-v1")
+	assert.Equal(t, "prod-db-v1", p.builtName("db"))
+
+	p = &createPipeline{Name: "db", KustomizationDir: t.TempDir()}
+	_, err = p.applyKustomizationDefaults(false)
+	assert.ErrorContains(t, err, "no kustomization.yaml found")
+}
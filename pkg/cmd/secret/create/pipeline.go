@@ -0,0 +1,406 @@
+package create
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/file"
+	"sopsctl/pkg/services/helpers"
+	"sopsctl/pkg/services/utils"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	kubectlcreate "k8s.io/kubectl/pkg/cmd/create"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/hash"
+)
+
+// createPipeline holds the options shared by all create commands and turns the secret
+// each of them builds into encrypted YAML.
+type createPipeline struct {
+	Name       string
+	Namespace  string
+	AppendHash bool
+	StringData bool
+	Cluster    string
+
+	Labels      []string
+	Annotations []string
+	Immutable   bool
+	DryRun      bool
+	labels      map[string]string
+	annotations map[string]string
+
+	// Output is the file the encrypted secret is written to, stdout when empty
+	Output             string
+	Force              bool
+	AddToKustomization bool
+	kustomizationPath  string
+	// KustomizationDir holds the kustomization the namespace and name conventions are
+	// taken from, the directory of Output when empty
+	KustomizationDir string
+	// namePrefix and nameSuffix are added to the name when kustomize builds the secret
+	namePrefix string
+	nameSuffix string
+
+	// IOStreams for output
+	IOStreams         genericiooptions.IOStreams
+	encryptionService domain.EncryptionService
+	sopsKeyManager    domain.SopsKeyManager
+	auditLog          domain.AuditLog
+}
+
+func newCreatePipeline(es domain.EncryptionService, skm domain.SopsKeyManager, auditLog domain.AuditLog) createPipeline {
+	return createPipeline{
+		encryptionService: es,
+		sopsKeyManager:    skm,
+		auditLog:          auditLog,
+	}
+}
+
+func (p *createPipeline) initCommonFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&p.AppendHash, "append-hash", p.AppendHash, "Append a hash of the secret to its name.")
+	cmd.Flags().BoolVar(&p.StringData, "string-data", p.StringData, "Write the values as plain text under stringData instead of base64 under data. Binary values stay under data.")
+	cmd.Flags().StringVarP(&p.Namespace, "namespace", "n", p.Namespace, "Namespace for the secret")
+	cmd.Flags().StringArrayVar(&p.Labels, "label", p.Labels, "Add a label to the secret (i.e. app=web), can be repeated")
+	cmd.Flags().StringArrayVar(&p.Annotations, "annotation", p.Annotations, "Add an annotation to the secret (i.e. kustomize.toolkit.fluxcd.io/reconcile=disabled), can be repeated")
+	cmd.Flags().BoolVar(&p.Immutable, "immutable", p.Immutable, "Mark the secret as immutable")
+	cmd.Flags().BoolVar(&p.DryRun, "dry-run", p.DryRun, "Print the secret with masked values and the recipients it would be encrypted for, without encrypting or writing it")
+	cmd.Flags().StringVarP(&p.Output, "output", "o", p.Output, "Write the encrypted secret to this file instead of stdout")
+	cmd.Flags().BoolVar(&p.Force, "force", p.Force, "Overwrite the output file if it already exists")
+	cmd.Flags().BoolVar(&p.AddToKustomization, "add-to-kustomization", p.AddToKustomization, "Add the output file to the resources of the kustomization.yaml in its directory")
+	cmd.Flags().StringVar(&p.KustomizationDir, "kustomization-dir", p.KustomizationDir, "Take the namespace, namePrefix and nameSuffix from the kustomization.yaml in this directory instead of the one in the output directory")
+}
+
+func (p *createPipeline) useCommonOptions(cmd *cobra.Command, args []string) error {
+	p.IOStreams = genericiooptions.IOStreams{
+		In:     cmd.InOrStdin(),
+		Out:    cmd.OutOrStdout(),
+		ErrOut: cmd.ErrOrStderr(),
+	}
+	flags, err := utils.UseGlobalFlags(cmd)
+	if err != nil {
+		return err
+	}
+	p.Cluster = flags.Cluster
+
+	// Parse the secret name from args
+	name, err := kubectlcreate.NameFromCommandArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	p.Name = name
+
+	// Get all flag values (flags have been parsed by cobra before UseOptions is called)
+	namespace, _ := cmd.Flags().GetString("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	p.Namespace = namespace
+	p.AppendHash, _ = cmd.Flags().GetBool("append-hash")
+	p.StringData, _ = cmd.Flags().GetBool("string-data")
+	p.Labels, _ = cmd.Flags().GetStringArray("label")
+	p.Annotations, _ = cmd.Flags().GetStringArray("annotation")
+	p.Immutable, _ = cmd.Flags().GetBool("immutable")
+	p.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	if err := p.parseMetadata(); err != nil {
+		return err
+	}
+	p.Output, _ = cmd.Flags().GetString("output")
+	p.Force, _ = cmd.Flags().GetBool("force")
+	p.AddToKustomization, _ = cmd.Flags().GetBool("add-to-kustomization")
+	p.KustomizationDir, _ = cmd.Flags().GetString("kustomization-dir")
+	if err := p.checkOutput(); err != nil {
+		return err
+	}
+	warnings, err := p.applyKustomizationDefaults(cmd.Flags().Changed("namespace"))
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		helpers.PrintWarning(warning)
+	}
+	if p.namePrefix != "" || p.nameSuffix != "" {
+		name := p.Name
+		if p.AppendHash {
+			name += "-<hash>"
+		}
+		_, _ = fmt.Fprintf(p.IOStreams.ErrOut, "The kustomization builds the secret as %s\n", p.builtName(name))
+	}
+	return nil
+}
+
+// builtName returns the name kustomize gives a secret of the given name, with the
+// namePrefix and nameSuffix of the target kustomization.
+func (p *createPipeline) builtName(name string) string {
+	return p.namePrefix + name + p.nameSuffix
+}
+
+// applyKustomizationDefaults takes the namespace from the kustomization the secret is
+// written for unless --namespace is given, and the namePrefix and nameSuffix kustomize
+// adds to its name. It returns a warning for each flag that
+// kustomize will override or repeat when it builds the secret.
+func (p *createPipeline) applyKustomizationDefaults(namespaceSet bool) ([]string, error) {
+	dir := p.KustomizationDir
+	if dir == "" {
+		if p.Output == "" {
+			return nil, nil
+		}
+		dir = filepath.Dir(p.Output)
+	}
+	kustomizationPath, err := findKustomization(dir)
+	if err != nil {
+		if p.KustomizationDir != "" {
+			return nil, err
+		}
+		// Writing into a directory without a kustomization is fine
+		return nil, nil
+	}
+	defaults, err := readKustomizationDefaults(kustomizationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	if defaults.Namespace != "" {
+		if !namespaceSet {
+			p.Namespace = defaults.Namespace
+		} else if p.Namespace != defaults.Namespace {
+			warnings = append(warnings, fmt.Sprintf("%s sets namespace %s, which replaces --namespace %s when it is built", kustomizationPath, defaults.Namespace, p.Namespace))
+		}
+	}
+	p.namePrefix = defaults.NamePrefix
+	p.nameSuffix = defaults.NameSuffix
+	if defaults.NamePrefix != "" && strings.HasPrefix(p.Name, defaults.NamePrefix) {
+		warnings = append(warnings, fmt.Sprintf("%s adds namePrefix %s, the secret will be built as %s%s", kustomizationPath, defaults.NamePrefix, defaults.NamePrefix, p.Name))
+	}
+	if defaults.NameSuffix != "" && strings.HasSuffix(p.Name, defaults.NameSuffix) {
+		warnings = append(warnings, fmt.Sprintf("%s adds nameSuffix %s, the secret will be built as %s%s", kustomizationPath, defaults.NameSuffix, p.Name, defaults.NameSuffix))
+	}
+	return warnings, nil
+}
+
+// checkOutput refuses to overwrite an existing output file without --force and looks up
+// the kustomization before anything is created, so a missing one fails early.
+func (p *createPipeline) checkOutput() error {
+	if p.Output == "" {
+		if p.AddToKustomization {
+			return fmt.Errorf("--add-to-kustomization requires --output")
+		}
+		return nil
+	}
+	output, err := filepath.Abs(p.Output)
+	if err != nil {
+		return err
+	}
+	p.Output = output
+	if err := p.checkOutputFile(); err != nil {
+		return err
+	}
+	if p.AddToKustomization {
+		p.kustomizationPath, err = findKustomization(filepath.Dir(p.Output))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOutputFile refuses an output that is a directory, or an existing file without --force
+// unless it is a dry run, which writes nothing.
+func (p *createPipeline) checkOutputFile() error {
+	if info, err := os.Stat(p.Output); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("output %s is a directory", p.Output)
+		}
+		if !p.Force && !p.DryRun {
+			return fmt.Errorf("output file %s already exists, use --force to overwrite it", p.Output)
+		}
+	}
+	return nil
+}
+
+// execute builds the secret, encrypts it and records the outcome in the audit log
+// under the given command. A dry run needs no private key and is not recorded.
+func (p *createPipeline) execute(command domain.CommandId, build func() (*corev1.Secret, error)) (string, error) {
+	result, dataKeys, err := p.create(build)
+	return p.write(command, result, dataKeys, err)
+}
+
+// write writes the secret returned by create to the output file, if there is one, and
+// records the outcome in the audit log.
+func (p *createPipeline) write(command domain.CommandId, result string, dataKeys []string, err error) (string, error) {
+	if p.DryRun {
+		return result, err
+	}
+	if err == nil && p.Output != "" {
+		result, err = p.writeOutput([]byte(result))
+	}
+	p.auditLog.Record(domain.NewAuditEntry(command, p.Cluster, p.Output, dataKeys, err))
+	return result, err
+}
+
+// writeOutput writes the encrypted secret to the output file and adds it to the
+// kustomization in its directory when asked to.
+func (p *createPipeline) writeOutput(encrypted []byte) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(p.Output), 0755); err != nil {
+		return "", fmt.Errorf("create output directory: %w", err)
+	}
+	// Checked again since a file may have been created while the values were prompted for
+	if err := p.checkOutputFile(); err != nil {
+		return "", err
+	}
+	if err := file.AtomicWriteFile(p.Output, encrypted); err != nil {
+		return "", err
+	}
+	result := fmt.Sprintf("Secret written to %s", color.GreenString(p.Output))
+	if p.kustomizationPath == "" {
+		return result, nil
+	}
+	resource := filepath.Base(p.Output)
+	added, err := addKustomizationResource(p.kustomizationPath, resource)
+	if err != nil {
+		return result, fmt.Errorf("secret written to %s but not added to %s: %w", p.Output, p.kustomizationPath, err)
+	}
+	if added {
+		return result + fmt.Sprintf("\nAdded %s to the resources of %s", resource, p.kustomizationPath), nil
+	}
+	return result + fmt.Sprintf("\n%s is already listed in %s", resource, p.kustomizationPath), nil
+}
+
+// create builds and encrypts the secret and returns the data keys it contains.
+func (p *createPipeline) create(build func() (*corev1.Secret, error)) (string, []string, error) {
+	secret, err := build()
+	if err != nil {
+		return "", nil, err
+	}
+	secret.Labels = p.labels
+	secret.Annotations = p.annotations
+	if p.Immutable {
+		immutable := true
+		secret.Immutable = &immutable
+	}
+	if p.AppendHash {
+		hashValue, err := hash.SecretHash(secret)
+		if err != nil {
+			return "", nil, err
+		}
+		secret.Name = fmt.Sprintf("%s-%s", secret.Name, hashValue)
+	}
+	var dataKeys []string
+	for key := range secret.Data {
+		dataKeys = append(dataKeys, key)
+	}
+	sort.Strings(dataKeys)
+	if p.StringData {
+		if binaryKeys := moveToStringData(secret); len(binaryKeys) > 0 {
+			helpers.PrintWarning(fmt.Sprintf("Values of %s are not valid UTF-8 and were kept base64 encoded under data", strings.Join(binaryKeys, ", ")))
+		}
+	}
+
+	publicKeys, err := p.sopsKeyManager.GetPublicKeys(p.Cluster)
+	if err != nil {
+		return "", dataKeys, err
+	}
+	if p.DryRun {
+		result, err := p.preview(secret, publicKeys)
+		return result, dataKeys, err
+	}
+
+	// Convert secret to YAML using Kubernetes printer (proper formatting with capitalized fields)
+	secretBytes, err := marshalSecretToYAML(secret)
+	if err != nil {
+		return "", dataKeys, fmt.Errorf("failed to marshal secret: %w", err)
+	}
+
+	encryptedSecret, err := p.encryptionService.EncryptData(secretBytes, publicKeys...)
+	if err != nil {
+		return "", dataKeys, fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	return string(encryptedSecret), dataKeys, nil
+}
+
+// parseMetadata parses and validates the labels and annotations given on the command line.
+func (p *createPipeline) parseMetadata() error {
+	var err error
+	p.labels, err = parseKeyValues("label", p.Labels, func(key, value string) []string {
+		return append(validation.IsQualifiedName(key), validation.IsValidLabelValue(value)...)
+	})
+	if err != nil {
+		return err
+	}
+	p.annotations, err = parseKeyValues("annotation", p.Annotations, func(key, _ string) []string {
+		return validation.IsQualifiedName(strings.ToLower(key))
+	})
+	return err
+}
+
+// parseKeyValues parses key=value pairs, checking each with validate and refusing keys
+// that are given twice.
+func parseKeyValues(kind string, pairs []string, validate func(key, value string) []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid %s %q, expected key=value", kind, pair)
+		}
+		if errs := validate(key, value); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid %s %q: %s", kind, pair, strings.Join(errs, "; "))
+		}
+		if _, exists := result[key]; exists {
+			return nil, fmt.Errorf("%s %s is given more than once", kind, key)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// marshalSecretToYAML uses the Kubernetes YAML printer to properly format the secret
+// This ensures correct field names (apiVersion, not apiversion) like kubectl does
+func marshalSecretToYAML(secret *corev1.Secret) ([]byte, error) {
+	// Create a YAML printer with the Kubernetes scheme
+	printer := printers.NewTypeSetter(scheme.Scheme).ToPrinter(&printers.YAMLPrinter{})
+
+	// Print to a buffer
+	var buf bytes.Buffer
+	if err := printer.PrintObj(secret, &buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// moveToStringData moves the UTF-8 values of the secret from data to stringData, so they
+// read as plain text once decrypted. It returns the keys of the binary values left in data.
+func moveToStringData(secret *corev1.Secret) []string {
+	var binaryKeys []string
+	for key, value := range secret.Data {
+		if !utf8.Valid(value) {
+			binaryKeys = append(binaryKeys, key)
+			continue
+		}
+		if secret.StringData == nil {
+			secret.StringData = map[string]string{}
+		}
+		secret.StringData[key] = string(value)
+		delete(secret.Data, key)
+	}
+	if len(secret.Data) == 0 {
+		secret.Data = nil
+	}
+	sort.Strings(binaryKeys)
+	return binaryKeys
+}
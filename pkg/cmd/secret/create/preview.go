@@ -0,0 +1,66 @@
+package create
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/yamlnode"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// preview renders the secret as it would be encrypted, with every value replaced by a
+// mask, preceded by the recipients and encrypted_regex the encryption would use.
+func (p *createPipeline) preview(secret *corev1.Secret, publicKeys []string) (string, error) {
+	secretBytes, err := marshalSecretToYAML(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal secret: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(secretBytes, &doc); err != nil {
+		return "", err
+	}
+	root := doc.Content[0]
+	maskValues(yamlnode.MappingValue(root, "data"), func(key string) []byte { return secret.Data[key] })
+	maskValues(yamlnode.MappingValue(root, "stringData"), func(key string) []byte { return []byte(secret.StringData[key]) })
+
+	var out bytes.Buffer
+	out.WriteString("# Dry run, nothing was encrypted or written\n")
+	fmt.Fprintf(&out, "# Recipients for %s:\n", p.Cluster)
+	for _, publicKey := range publicKeys {
+		fmt.Fprintf(&out, "#   %s\n", publicKey)
+	}
+	fmt.Fprintf(&out, "# encrypted_regex: %s\n", domain.DefaultEncryptedRegex)
+	if p.Output != "" {
+		fmt.Fprintf(&out, "# Output: %s\n", p.Output)
+	}
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// maskValues replaces the values of a data or stringData mapping with their mask.
+func maskValues(values *yaml.Node, raw func(key string) []byte) {
+	if values == nil {
+		return
+	}
+	for i := 0; i+1 < len(values.Content); i += 2 {
+		values.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: maskValue(raw(values.Content[i].Value))}
+	}
+}
+
+// maskValue describes a value by its length and a short hash, enough to tell values
+// apart and spot an empty or truncated one without revealing it.
+func maskValue(value []byte) string {
+	sum := sha256.Sum256(value)
+	return fmt.Sprintf("<%d bytes, sha256:%x>", len(value), sum[:4])
+}
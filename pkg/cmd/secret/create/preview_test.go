@@ -0,0 +1,28 @@
+package create
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPreview_MasksValues(t *testing.T) {
+	secret := newSecretObj("db", "apps", corev1.SecretTypeOpaque)
+	secret.Data["password"] = []byte("hunter2")
+	p := &createPipeline{Cluster: "prod"}
+
+	result, err := p.preview(secret, []string{"age1recipient"})
+	require.NoError(t, err)
+	assert.Contains(t, result, "#   age1recipient")
+	assert.Contains(t, result, "# encrypted_regex: ^(data|stringData)$")
+	assert.Contains(t, result, "password: <7 bytes, sha256:f52fbd32>")
+	assert.NotContains(t, result, "hunter2")
+	assert.NotContains(t, result, "aHVudGVyMg==")
+}
+
+func TestMaskValue_DiffersForDifferentValues(t *testing.T) {
+	assert.Equal(t, maskValue([]byte("a")), maskValue([]byte("a")))
+	assert.NotEqual(t, maskValue([]byte("a")), maskValue([]byte("b")))
+}
@@ -1,23 +1,18 @@
 package create
 
 import (
-	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"sopsctl/pkg/services/utils"
+	"sopsctl/pkg/services/generate"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
-	"k8s.io/cli-runtime/pkg/genericiooptions"
-	"k8s.io/cli-runtime/pkg/printers"
-	kubectlcreate "k8s.io/kubectl/pkg/cmd/create"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
-	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util"
-	"k8s.io/kubectl/pkg/util/hash"
 	"k8s.io/kubectl/pkg/util/i18n"
 
 	"sopsctl/pkg/domain"
@@ -27,61 +22,62 @@ import (
 
 // SecretCreateCmd wraps kubectl's secret creation logic with encryption
 type SecretCreateCmd struct {
+	createPipeline
+
 	// Options mirror kubectl's CreateSecretOptions but without the k8s client
-	Name           string
 	Type           string
 	FileSources    []string
 	LiteralSources []string
 	EnvFileSources []string
-	AppendHash     bool
-	Namespace      string
-	Cluster        string
-
-	// IOStreams for output
-	IOStreams         genericiooptions.IOStreams
-	encryptionService domain.EncryptionService
-	sopsKeyManager    domain.SopsKeyManager
+	// RandomSources are key=length[:charset] specs whose values are generated
+	RandomSources []string
+	// StdinSource is the key whose value is read from stdin
+	StdinSource string
+	// PromptSources are the keys whose values are entered at a hidden terminal prompt
+	PromptSources []string
+	// SSHKeyPairSources, TLSSource and WireGuardSource generate key material in memory
+	SSHKeyPairSources []string
+	TLSSource         string
+	WireGuardSource   string
 }
 
-func NewSecretCreateCmd(es domain.EncryptionService, skm domain.SopsKeyManager) *SecretCreateCmd {
-	return &SecretCreateCmd{
-		encryptionService: es,
-		sopsKeyManager:    skm,
-	}
+func NewSecretCreateCmd(es domain.EncryptionService, skm domain.SopsKeyManager, auditLog domain.AuditLog) *SecretCreateCmd {
+	return &SecretCreateCmd{createPipeline: newCreatePipeline(es, skm, auditLog)}
 }
 
 func (s *SecretCreateCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
-	s.IOStreams = genericiooptions.IOStreams{
-		In:     cmd.InOrStdin(),
-		Out:    cmd.OutOrStdout(),
-		ErrOut: cmd.ErrOrStderr(),
-	}
-	flags, err := utils.UseGlobalFlags(cmd)
-	if err != nil {
+	if err := s.useCommonOptions(cmd, args); err != nil {
 		return nil, err
 	}
-	s.Cluster = flags.Cluster
-
-	// Parse the secret name from args
-	name, err := kubectlcreate.NameFromCommandArgs(cmd, args)
-	if err != nil {
-		return nil, err
-	}
-	s.Name = name
-
-	// Get all flag values (flags have been parsed by cobra before UseOptions is called)
-	namespace, _ := cmd.Flags().GetString("namespace")
-	if namespace == "" {
-		namespace = "default"
-	}
-	s.Namespace = namespace
 
 	// Get the secret creation source flags
 	s.FileSources, _ = cmd.Flags().GetStringSlice("from-file")
 	s.LiteralSources, _ = cmd.Flags().GetStringArray("from-literal")
 	s.EnvFileSources, _ = cmd.Flags().GetStringSlice("from-env-file")
 	s.Type, _ = cmd.Flags().GetString("type")
-	s.AppendHash, _ = cmd.Flags().GetBool("append-hash")
+	s.RandomSources, _ = cmd.Flags().GetStringArray("from-random")
+	s.StdinSource, _ = cmd.Flags().GetString("from-stdin")
+	s.PromptSources, _ = cmd.Flags().GetStringArray("prompt")
+	// Check the specs and keys before anything is read, generated or prompted for
+	for _, source := range s.RandomSources {
+		if _, _, err := generate.ParseRandomSource(source); err != nil {
+			return nil, err
+		}
+	}
+	s.SSHKeyPairSources, _ = cmd.Flags().GetStringArray("generate-ssh-keypair")
+	s.TLSSource, _ = cmd.Flags().GetString("generate-tls")
+	s.WireGuardSource, _ = cmd.Flags().GetString("generate-wireguard")
+	if err := s.validateGenerators(); err != nil {
+		return nil, err
+	}
+	if s.StdinSource != "" && len(s.PromptSources) > 0 {
+		return nil, fmt.Errorf("--from-stdin cannot be combined with --prompt, both read from stdin")
+	}
+	for _, key := range append([]string{s.StdinSource}, s.PromptSources...) {
+		if errs := validation.IsConfigMapKey(key); key != "" && len(errs) != 0 {
+			return nil, fmt.Errorf("%q is not valid key name for a Secret %s", key, strings.Join(errs, ";"))
+		}
+	}
 
 	return s, nil
 }
@@ -91,55 +87,30 @@ func (s *SecretCreateCmd) InitCmd(cmd *cobra.Command) {
 	cmd.Flags().StringSliceVar(&s.FileSources, "from-file", s.FileSources, "Key files can be specified using their file path, in which case a default name will be given to them, or optionally with a name and file path, in which case the given name will be used.  Specifying a directory will iterate each named file in the directory that is a valid secret key.")
 	cmd.Flags().StringArrayVar(&s.LiteralSources, "from-literal", s.LiteralSources, "Specify a key and literal value to insert in secret (i.e. mykey=somevalue)")
 	cmd.Flags().StringSliceVar(&s.EnvFileSources, "from-env-file", s.EnvFileSources, "Specify the path to a file to read lines of key=val pairs to create a secret.")
+	cmd.Flags().StringArrayVar(&s.RandomSources, "from-random", s.RandomSources, "Generate a random value for a key (i.e. db-password=32:alnum, token=64:hex or secret=32:base64). The value is never printed.")
+	cmd.Flags().StringVar(&s.StdinSource, "from-stdin", s.StdinSource, "Read the value of this key from stdin, so it never appears in the shell history")
+	cmd.Flags().StringArrayVar(&s.PromptSources, "prompt", s.PromptSources, "Prompt for the value of this key without echoing it, can be repeated")
+	cmd.Flags().StringArrayVar(&s.SSHKeyPairSources, "generate-ssh-keypair", s.SSHKeyPairSources, "Generate an SSH key pair with key[=ed25519|ecdsa|rsa], the private key is stored under key and the public key under key.pub")
+	cmd.Flags().StringVar(&s.TLSSource, "generate-tls", s.TLSSource, "Generate a certificate and key under tls.crt and tls.key with cn=name[,days=365][,dns=a;b][,is-ca][,ca=ca-secret.enc.yaml]")
+	cmd.Flags().StringVar(&s.WireGuardSource, "generate-wireguard", s.WireGuardSource, "Generate a WireGuard key pair, the private key is stored under the given key and the public key under key.pub")
+	cmd.Flags().Lookup("generate-wireguard").NoOptDefVal = defaultWireGuardKey
 	cmd.Flags().StringVar(&s.Type, "type", s.Type, i18n.T("The type of secret to create"))
-	cmd.Flags().BoolVar(&s.AppendHash, "append-hash", s.AppendHash, "Append a hash of the secret to its name.")
-	cmd.Flags().StringVarP(&s.Namespace, "namespace", "n", s.Namespace, "Namespace for the secret")
+	s.initCommonFlags(cmd)
 }
 
 func (s *SecretCreateCmd) Execute() (string, error) {
-	// Validate inputs
-	if err := s.Validate(); err != nil {
-		return "", err
-	}
-
-	// Create the secret object using kubectl's logic
-	secret, err := s.createSecret()
-	if err != nil {
-		return "", fmt.Errorf("failed to create secret: %w", err)
-	}
-
-	publicKey, err := s.sopsKeyManager.GetPublicKey(s.Cluster)
-	if err != nil {
-		return "", err
-	}
-
-	// Convert secret to YAML using Kubernetes printer (proper formatting with capitalized fields)
-	secretBytes, err := s.marshalSecretToYAML(secret)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal secret: %w", err)
-	}
-
-	encryptedSecret, err := s.encryptionService.EncryptData(secretBytes, publicKey)
-	if err != nil {
-		return "", fmt.Errorf("failed to encrypt secret: %w", err)
-	}
-
-	return string(encryptedSecret), nil
-}
-
-// marshalSecretToYAML uses the Kubernetes YAML printer to properly format the secret
-// This ensures correct field names (apiVersion, not apiversion) like kubectl does
-func (s *SecretCreateCmd) marshalSecretToYAML(secret *corev1.Secret) ([]byte, error) {
-	// Create a YAML printer with the Kubernetes scheme
-	printer := printers.NewTypeSetter(scheme.Scheme).ToPrinter(&printers.YAMLPrinter{})
-
-	// Print to a buffer
-	var buf bytes.Buffer
-	if err := printer.PrintObj(secret, &buf); err != nil {
-		return nil, err
-	}
-
-	return buf.Bytes(), nil
+	return s.execute(domain.SecretCreate, func() (*corev1.Secret, error) {
+		// Validate inputs
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+		// Create the secret object using kubectl's logic
+		secret, err := s.createSecret()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create secret: %w", err)
+		}
+		return secret, nil
+	})
 }
 
 func (s *SecretCreateCmd) Validate() error {
@@ -172,12 +143,29 @@ func (s *SecretCreateCmd) createSecret() (*corev1.Secret, error) {
 			return nil, err
 		}
 	}
-	if s.AppendHash {
-		hashValue, err := hash.SecretHash(secret)
+	if err := handleSecretFromRandomSources(secret, s.RandomSources); err != nil {
+		return nil, err
+	}
+	if s.StdinSource != "" {
+		value, err := readStdinValue(s.IOStreams.In)
 		if err != nil {
 			return nil, err
 		}
-		secret.Name = fmt.Sprintf("%s-%s", secret.Name, hashValue)
+		if err := addKeyFromLiteralToSecret(secret, s.StdinSource, value); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.PromptSources) > 0 {
+		read, err := terminalPasswordReader(s.IOStreams.In)
+		if err != nil {
+			return nil, fmt.Errorf("--prompt requires an interactive terminal: %w", err)
+		}
+		if err := handleSecretFromPromptSources(secret, s.PromptSources, read, s.IOStreams.ErrOut); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.handleSecretFromGenerators(secret); err != nil {
+		return nil, err
 	}
 
 	return secret, nil
@@ -256,6 +244,39 @@ func handleSecretFromFileSources(secret *corev1.Secret, fileSources []string) er
 	return nil
 }
 
+func handleSecretFromRandomSources(secret *corev1.Secret, randomSources []string) error {
+	for _, randomSource := range randomSources {
+		keyName, spec, err := generate.ParseRandomSource(randomSource)
+		if err != nil {
+			return err
+		}
+		value, err := spec.Generate()
+		if err != nil {
+			return err
+		}
+		if err := addKeyFromLiteralToSecret(secret, keyName, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handleSecretFromPromptSources(secret *corev1.Secret, promptSources []string, read passwordReader, out io.Writer) error {
+	for _, keyName := range promptSources {
+		if _, entryExists := secret.Data[keyName]; entryExists {
+			return fmt.Errorf("cannot add key %s, another key by that name already exists", keyName)
+		}
+		value, err := promptValue(keyName, read, out)
+		if err != nil {
+			return err
+		}
+		if err := addKeyFromLiteralToSecret(secret, keyName, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func handleSecretFromEnvFileSources(secret *corev1.Secret, envFileSources []string) error {
 	for _, envFileSource := range envFileSources {
 		info, err := os.Stat(envFileSource)
@@ -0,0 +1,132 @@
+package create
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestMoveToStringData_KeepsBinaryValuesInData(t *testing.T) {
+	secret := newSecretObj("app", "default", corev1.SecretTypeOpaque)
+	secret.Data["password"] = []byte("hunter2")
+	secret.Data["keystore"] = []byte{0xff, 0xfe, 0x00}
+
+	binaryKeys := moveToStringData(secret)
+
+	assert.Equal(t, []string{"keystore"}, binaryKeys)
+	assert.Equal(t, map[string]string{"password": "hunter2"}, secret.StringData)
+	assert.Equal(t, map[string][]byte{"keystore": {0xff, 0xfe, 0x00}}, secret.Data)
+}
+
+func TestMoveToStringData_DropsEmptyData(t *testing.T) {
+	secret := newSecretObj("app", "default", corev1.SecretTypeOpaque)
+	secret.Data["username"] = []byte("admin")
+
+	assert.Empty(t, moveToStringData(secret))
+	assert.Nil(t, secret.Data)
+	assert.Equal(t, "admin", secret.StringData["username"])
+}
+
+func writeTestKeyPair(t *testing.T, dir string, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certPath, keyPath
+}
+
+func TestCreateSecretTLS(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeTestKeyPair(t, dir, "web")
+	uut := &SecretCreateTLSCmd{createPipeline: createPipeline{Name: "web", Namespace: "default"}, Cert: certPath, Key: keyPath}
+
+	secret, err := uut.createSecretTLS()
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeTLS, secret.Type)
+	assert.Contains(t, string(secret.Data[corev1.TLSCertKey]), "BEGIN CERTIFICATE")
+	assert.Contains(t, string(secret.Data[corev1.TLSPrivateKeyKey]), "BEGIN EC PRIVATE KEY")
+}
+
+func TestCreateSecretTLS_RejectsMismatchedKey(t *testing.T) {
+	dir := t.TempDir()
+	certPath, _ := writeTestKeyPair(t, dir, "web")
+	_, otherKeyPath := writeTestKeyPair(t, dir, "other")
+	uut := &SecretCreateTLSCmd{createPipeline: createPipeline{Name: "web", Namespace: "default"}, Cert: certPath, Key: otherKeyPath}
+
+	_, err := uut.createSecretTLS()
+	assert.ErrorContains(t, err, "invalid certificate and key pair")
+}
+
+func TestCreateSecretDockerRegistry(t *testing.T) {
+	uut := &SecretCreateDockerRegistryCmd{
+		createPipeline: createPipeline{Name: "registry", Namespace: "default"},
+		Username:       "tiger",
+		Password:       "pass1234",
+		Server:         "ghcr.io",
+	}
+
+	secret, err := uut.createSecretDockerRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+	assert.JSONEq(t,
+		`{"auths":{"ghcr.io":{"username":"tiger","password":"pass1234","auth":"dGlnZXI6cGFzczEyMzQ="}}}`,
+		string(secret.Data[corev1.DockerConfigJsonKey]))
+}
+
+func TestParseMetadata(t *testing.T) {
+	p := &createPipeline{
+		Labels:      []string{"app=web", "reflector=true"},
+		Annotations: []string{"kustomize.toolkit.fluxcd.io/reconcile=disabled", "note=a=b"},
+	}
+
+	require.NoError(t, p.parseMetadata())
+	assert.Equal(t, map[string]string{"app": "web", "reflector": "true"}, p.labels)
+	assert.Equal(t, map[string]string{"kustomize.toolkit.fluxcd.io/reconcile": "disabled", "note": "a=b"}, p.annotations)
+}
+
+func TestParseMetadata_RejectsInvalidPairs(t *testing.T) {
+	for _, p := range []*createPipeline{
+		{Labels: []string{"app"}},
+		{Labels: []string{"app=two words"}},
+		{Labels: []string{"app=web", "app=api"}},
+		{Annotations: []string{"=value"}},
+	} {
+		assert.Error(t, p.parseMetadata())
+	}
+}
+
+func TestHandleSecretFromRandomSources(t *testing.T) {
+	secret := newSecretObj("app", "default", corev1.SecretTypeOpaque)
+	secret.Data["token"] = []byte("literal")
+
+	require.NoError(t, handleSecretFromRandomSources(secret, []string{"db-password=32:alnum"}))
+	assert.Len(t, secret.Data["db-password"], 32)
+
+	err := handleSecretFromRandomSources(secret, []string{"token=64:hex"})
+	assert.ErrorContains(t, err, "another key by that name already exists")
+}
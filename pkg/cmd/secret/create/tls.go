@@ -0,0 +1,66 @@
+package create
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sopsctl/pkg/domain"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SecretCreateTLSCmd mirrors kubectl create secret tls and encrypts the result
+type SecretCreateTLSCmd struct {
+	createPipeline
+
+	Cert string
+	Key  string
+}
+
+func NewSecretCreateTLSCmd(es domain.EncryptionService, skm domain.SopsKeyManager, auditLog domain.AuditLog) *SecretCreateTLSCmd {
+	return &SecretCreateTLSCmd{createPipeline: newCreatePipeline(es, skm, auditLog)}
+}
+
+func (s *SecretCreateTLSCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.Cert, "cert", s.Cert, "Path to PEM encoded public key certificate.")
+	cmd.Flags().StringVar(&s.Key, "key", s.Key, "Path to private key associated with given certificate.")
+	s.initCommonFlags(cmd)
+}
+
+func (s *SecretCreateTLSCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	if err := s.useCommonOptions(cmd, args); err != nil {
+		return nil, err
+	}
+	s.Cert, _ = cmd.Flags().GetString("cert")
+	s.Key, _ = cmd.Flags().GetString("key")
+	if len(s.Cert) == 0 || len(s.Key) == 0 {
+		return nil, fmt.Errorf("--cert and --key must both be specified")
+	}
+	return s, nil
+}
+
+func (s *SecretCreateTLSCmd) Execute() (string, error) {
+	return s.execute(domain.SecretCreateTLS, s.createSecretTLS)
+}
+
+// createSecretTLS is based on kubectl's CreateSecretTLSOptions.createSecretTLS()
+func (s *SecretCreateTLSCmd) createSecretTLS() (*corev1.Secret, error) {
+	tlsCert, err := os.ReadFile(s.Cert)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read certificate %s: %w", s.Cert, err)
+	}
+	tlsKey, err := os.ReadFile(s.Key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read private key %s: %w", s.Key, err)
+	}
+	// X509KeyPair parses both PEM blocks and checks that the key belongs to the certificate
+	if _, err := tls.X509KeyPair(tlsCert, tlsKey); err != nil {
+		return nil, fmt.Errorf("invalid certificate and key pair: %w", err)
+	}
+
+	secret := newSecretObj(s.Name, s.Namespace, corev1.SecretTypeTLS)
+	secret.Data[corev1.TLSCertKey] = tlsCert
+	secret.Data[corev1.TLSPrivateKeyKey] = tlsKey
+	return secret, nil
+}
@@ -2,6 +2,7 @@ package decrypt
 
 import (
 	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/audit"
 	"sopsctl/pkg/services/utils"
 
 	"github.com/spf13/cobra"
@@ -11,6 +12,7 @@ type SecretDecryptCmd struct {
 	options           *SecretDecryptOptions
 	keyManager        domain.SopsKeyManager
 	encryptionService domain.EncryptionService
+	auditLog          domain.AuditLog
 }
 
 func (d SecretDecryptCmd) InitCmd(_ *cobra.Command) {
@@ -18,33 +20,36 @@ func (d SecretDecryptCmd) InitCmd(_ *cobra.Command) {
 	panic("implement me")
 }
 
-func NewSecretDecryptCmd(keyManager domain.SopsKeyManager, encryptionService domain.EncryptionService) *SecretDecryptCmd {
-	return &SecretDecryptCmd{keyManager: keyManager, encryptionService: encryptionService}
+func NewSecretDecryptCmd(keyManager domain.SopsKeyManager, encryptionService domain.EncryptionService, auditLog domain.AuditLog) *SecretDecryptCmd {
+	return &SecretDecryptCmd{keyManager: keyManager, encryptionService: encryptionService, auditLog: auditLog}
 }
 
 func (d SecretDecryptCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
-	gFlags, err := utils.UseGlobalFlags(cmd)
+	check, err := utils.UserFileArg(args)
 	if err != nil {
 		return nil, err
 	}
-	check, err := utils.UserFileArg(args)
+	cluster, err := utils.UseDecryptCluster(cmd, check, d.keyManager, d.encryptionService)
 	if err != nil {
 		return nil, err
 	}
-	d.options = NewSecretDecryptOptions(check, gFlags.Cluster)
+	d.options = NewSecretDecryptOptions(check, cluster)
 	return d, nil
 }
 
 func (d SecretDecryptCmd) Execute() (string, error) {
-	var output string
-	privateKey, err := d.keyManager.GetPrivateKey(d.options.Cluster)
+	decrypted, err := d.decrypt()
+	d.auditLog.Record(domain.NewAuditEntry(domain.SecretDecrypt, d.options.Cluster, d.options.FilePath, audit.DataKeys(decrypted), err))
 	if err != nil {
 		return "", err
 	}
-	decrypted, err := d.encryptionService.Decrypt(d.options.FilePath, privateKey)
+	return string(decrypted), nil
+}
+
+func (d SecretDecryptCmd) decrypt() ([]byte, error) {
+	privateKey, err := d.keyManager.GetPrivateKey(d.options.Cluster)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	output = string(decrypted)
-	return output, nil
+	return d.encryptionService.Decrypt(d.options.FilePath, privateKey)
 }
@@ -3,6 +3,7 @@ package edit
 import (
 	"fmt"
 	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/audit"
 	"sopsctl/pkg/services/file"
 	"sopsctl/pkg/services/utils"
 
@@ -22,6 +23,7 @@ type SecretEditCmd struct {
 	decoder           domain.Base64Decoder
 	editor            domain.UserEditorService
 	fileService       domain.FileService
+	auditLog          domain.AuditLog
 }
 
 func (e SecretEditCmd) InitCmd(cmd *cobra.Command) {
@@ -36,42 +38,64 @@ You can also specify the key within the secret to edit -d -k <key>.
 }
 
 // NewSecretEditCmd Updated constructor with dependencies for DI container.
-func NewSecretEditCmd(keyManager domain.SopsKeyManager, encryptionService domain.EncryptionService, decoder domain.Base64Decoder, editor domain.UserEditorService, fileService domain.FileService) domain.CommandBuilder {
+func NewSecretEditCmd(keyManager domain.SopsKeyManager, encryptionService domain.EncryptionService, decoder domain.Base64Decoder, editor domain.UserEditorService, fileService domain.FileService, auditLog domain.AuditLog) domain.CommandBuilder {
 	return &SecretEditCmd{
 		keyManager:        keyManager,
 		encryptionService: encryptionService,
 		decoder:           decoder,
 		editor:            editor,
 		fileService:       fileService,
+		auditLog:          auditLog,
 	}
 }
 
 // Execute orchestrates the edit workflow: decrypt, decode (if needed), edit, encode, encrypt, and save.
 func (e SecretEditCmd) Execute() (string, error) {
-	decrypted, err := e.decryptFile()
+	result, changedKeys, err := e.edit()
+	e.auditLog.Record(domain.NewAuditEntry(domain.SecretEdit, e.options.Cluster, e.options.File, changedKeys, err))
+	return result, err
+}
+
+// edit runs the edit workflow and returns the data keys that were changed.
+func (e SecretEditCmd) edit() (string, []string, error) {
+	// Read before decrypting, the file is re-encrypted with the same regex
+	encryptedRegex, err := e.encryptionService.EncryptedRegex(e.options.File)
+	if err != nil {
+		return "", nil, err
+	}
+	original, err := e.decryptFile()
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	decrypted, reEncodeFunc, err := e.decodeIfNeeded(decrypted)
+	decrypted, reEncodeFunc, err := e.decodeIfNeeded(original)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	copyOfDecrypted := &decrypted
 	editedContent, err := e.editInTempFile(decrypted)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	// If no changes were made, exit early
 	if string(editedContent) == string(*copyOfDecrypted) {
-		return "No changes made to the file", nil
+		return "No changes made to the file", nil, nil
+	}
+
+	changedKeys := audit.ChangedDataKeys(original, editedContent)
+	if e.options.ShouldDecodeAsFile {
+		valueKey, err := e.resolveDecodeKey(original)
+		if err != nil {
+			return "", nil, err
+		}
+		changedKeys = []string{valueKey}
 	}
 
-	if err := e.encryptAndSave(editedContent, reEncodeFunc); err != nil {
-		return "", err
+	if err := e.encryptAndSave(editedContent, reEncodeFunc, encryptedRegex); err != nil {
+		return "", changedKeys, err
 	}
 
-	return "File edited and encrypted successfully", nil
+	return "File edited and encrypted successfully", changedKeys, nil
 }
 
 // decryptFile retrieves the private key and decrypts the target file.
@@ -144,19 +168,20 @@ func (e SecretEditCmd) editInTempFile(content []byte) ([]byte, error) {
 	return editedContent, nil
 }
 
-// encryptAndSave re-encodes (if needed), encrypts the content, and writes it back to the original file.
-func (e SecretEditCmd) encryptAndSave(editedContent []byte, reEncodeFunc func([]byte) ([]byte, error)) error {
-	publicKey, err := e.keyManager.GetPublicKey(e.options.Cluster)
+// encryptAndSave re-encodes (if needed), encrypts the content with the encrypted regex of the
+// original file, and writes it back to the original file.
+func (e SecretEditCmd) encryptAndSave(editedContent []byte, reEncodeFunc func([]byte) ([]byte, error), encryptedRegex string) error {
+	encodedData, err := reEncodeFunc(editedContent)
 	if err != nil {
-		return fmt.Errorf("failed to get SOPS public key for cluster %s: %w", e.options.Cluster, err)
+		return fmt.Errorf("failed to re-encode data: %w", err)
 	}
 
-	encodedData, err := reEncodeFunc(editedContent)
+	publicKeys, err := e.reEncryptionKeys()
 	if err != nil {
-		return fmt.Errorf("failed to re-encode data: %w", err)
+		return err
 	}
 
-	encrypted, err := e.encryptionService.EncryptData(encodedData, publicKey)
+	encrypted, err := e.encryptionService.EncryptDataWithRegex(encodedData, encryptedRegex, publicKeys...)
 	if err != nil {
 		return fmt.Errorf("failed to re-encrypt file: %w", err)
 	}
@@ -168,16 +193,34 @@ func (e SecretEditCmd) encryptAndSave(editedContent []byte, reEncodeFunc func([]
 	return nil
 }
 
+// reEncryptionKeys returns the age recipients the file is encrypted for, so a file shared by a
+// cluster group stays readable by every member. Files without age recipients are encrypted
+// for the cluster the file was decrypted with.
+func (e SecretEditCmd) reEncryptionKeys() ([]string, error) {
+	recipients, err := e.encryptionService.Recipients(e.options.File)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) > 0 {
+		return recipients, nil
+	}
+	publicKey, err := e.keyManager.GetPublicKey(e.options.Cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SOPS public key for cluster %s: %w", e.options.Cluster, err)
+	}
+	return []string{publicKey}, nil
+}
+
 // atomicWriteFile is a variable to allow mocking in tests
 var atomicWriteFile = file.AtomicWriteFile
 
 func (e SecretEditCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
-	global, err := utils.UseGlobalFlags(cmd)
+	filePath, err := utils.UserFileArg(args)
 	if err != nil {
 		return nil, err
 	}
 
-	filePath, err := utils.UserFileArg(args)
+	cluster, err := utils.UseDecryptCluster(cmd, filePath, e.keyManager, e.encryptionService)
 	if err != nil {
 		return nil, err
 	}
@@ -197,6 +240,6 @@ func (e SecretEditCmd) UseOptions(cmd *cobra.Command, args []string) (domain.Com
 		return nil, err
 	}
 
-	e.options = newEditCmdOptions(filePath, global.Cluster, shouldDecodeAsEnv, shouldDecodeAsFile, shouldDecodeDataKey)
+	e.options = newEditCmdOptions(filePath, cluster, shouldDecodeAsEnv, shouldDecodeAsFile, shouldDecodeDataKey)
 	return e, nil
 }
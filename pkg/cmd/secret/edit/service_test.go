@@ -3,6 +3,11 @@ package edit
 import (
 	"errors"
 	"io"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/encryption"
+	"strings"
 	"testing"
 
 	"filippo.io/age"
@@ -42,11 +47,54 @@ func (m *mockKeyManager) RemoveKeyForContext(_ string) error {
 	return nil
 }
 
+func (m *mockKeyManager) GetStorageMode(_ string) (domain.StorageMode, error) {
+	return domain.LocalStorageMode, nil
+}
+
+func (m *mockKeyManager) AddPublicKey(_, _ string) (string, error) {
+	return "", nil
+}
+
+func (m *mockKeyManager) IsEncryptOnly(_ string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockKeyManager) GetCtx(_ string) (*domain.CTX, error) {
+	return domain.NewEmptyCtx(), nil
+}
+
+func (m *mockKeyManager) RefreshKey(_ string) (domain.KeyRefreshStatus, error) {
+	return domain.KeyRefreshUnchanged, nil
+}
+
+func (m *mockKeyManager) ExportCtx(_ string) (*domain.CTX, error) {
+	return domain.NewEmptyCtx(), nil
+}
+
+func (m *mockKeyManager) ImportCtx(_ string, _ domain.CTX) error {
+	return nil
+}
+
+func (m *mockKeyManager) FindCtxForRecipients(_ []string, preferred string) (string, error) {
+	return preferred, nil
+}
+
+func (m *mockKeyManager) ResolveClusters(cluster string) ([]string, error) {
+	return []string{cluster}, nil
+}
+
+func (m *mockKeyManager) GetPublicKeys(_ string) ([]string, error) {
+	return []string{m.publicKey}, m.publicKeyErr
+}
+
 type mockEncryptionService struct {
-	decryptedData []byte
-	encryptedData []byte
-	decryptErr    error
-	encryptErr    error
+	recipients     []string
+	encryptedRegex string
+	encryptedWith  []string
+	decryptedData  []byte
+	encryptedData  []byte
+	decryptErr     error
+	encryptErr     error
 }
 
 func (m *mockEncryptionService) Decrypt(_, _ string) ([]byte, error) {
@@ -61,14 +109,28 @@ func (m *mockEncryptionService) SopsDecryptWithFormat(_ []byte, _, _ formats.For
 	return nil, nil
 }
 
-func (m *mockEncryptionService) EncryptFile(_, _ string) ([]byte, error) {
+func (m *mockEncryptionService) EncryptFile(_ string, _ string, _ ...string) ([]byte, error) {
+	return m.encryptedData, m.encryptErr
+}
+
+func (m *mockEncryptionService) EncryptData(_ []byte, publicKeys ...string) ([]byte, error) {
+	m.encryptedWith = publicKeys
 	return m.encryptedData, m.encryptErr
 }
 
-func (m *mockEncryptionService) EncryptData(_ []byte, _ string) ([]byte, error) {
+func (m *mockEncryptionService) EncryptDataWithRegex(_ []byte, _ string, publicKeys ...string) ([]byte, error) {
+	m.encryptedWith = publicKeys
 	return m.encryptedData, m.encryptErr
 }
 
+func (m *mockEncryptionService) Recipients(_ string) ([]string, error) {
+	return m.recipients, nil
+}
+
+func (m *mockEncryptionService) EncryptedRegex(_ string) (string, error) {
+	return m.encryptedRegex, nil
+}
+
 type mockDecoder struct {
 	defaultKey     string
 	decodedData    []byte
@@ -487,7 +549,7 @@ func TestEncryptAndSave_Success(t *testing.T) {
 		return b, nil
 	}
 
-	err := cmd.encryptAndSave(editedContent, reEncodeFunc)
+	err := cmd.encryptAndSave(editedContent, reEncodeFunc, domain.DefaultEncryptedRegex)
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
@@ -497,6 +559,42 @@ func TestEncryptAndSave_Success(t *testing.T) {
 	}
 }
 
+func TestEncryptAndSave_KeepsFileRecipients(t *testing.T) {
+	mockKM := &mockKeyManager{
+		publicKey: "test-public-key",
+	}
+	mockEnc := &mockEncryptionService{
+		recipients:    []string{"age1prodeu", "age1produs"},
+		encryptedData: []byte("encrypted data"),
+	}
+
+	originalWrite := atomicWriteFile
+	atomicWriteFile = func(_ string, _ []byte) error {
+		return nil
+	}
+	defer func() { atomicWriteFile = originalWrite }()
+
+	cmd := SecretEditCmd{
+		keyManager:        mockKM,
+		encryptionService: mockEnc,
+		options: &editCmdOptions{
+			File:    "test.yaml",
+			Cluster: "prod-eu",
+		},
+	}
+
+	err := cmd.encryptAndSave([]byte("edited content"), func(b []byte) ([]byte, error) {
+		return b, nil
+	}, domain.DefaultEncryptedRegex)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(mockEnc.encryptedWith) != 2 || mockEnc.encryptedWith[0] != "age1prodeu" || mockEnc.encryptedWith[1] != "age1produs" {
+		t.Errorf("Expected the file's recipients to be kept, got %v", mockEnc.encryptedWith)
+	}
+}
+
 func TestEncryptAndSave_PublicKeyError(t *testing.T) {
 	expectedErr := errors.New("public key not found")
 	mockKM := &mockKeyManager{
@@ -504,7 +602,8 @@ func TestEncryptAndSave_PublicKeyError(t *testing.T) {
 	}
 
 	cmd := SecretEditCmd{
-		keyManager: mockKM,
+		keyManager:        mockKM,
+		encryptionService: &mockEncryptionService{},
 		options: &editCmdOptions{
 			Cluster: "test-cluster",
 		},
@@ -514,7 +613,7 @@ func TestEncryptAndSave_PublicKeyError(t *testing.T) {
 		return b, nil
 	}
 
-	err := cmd.encryptAndSave([]byte("data"), reEncodeFunc)
+	err := cmd.encryptAndSave([]byte("data"), reEncodeFunc, domain.DefaultEncryptedRegex)
 
 	if err == nil {
 		t.Error("Expected error, got nil")
@@ -541,7 +640,7 @@ func TestEncryptAndSave_ReEncodeError(t *testing.T) {
 		return nil, expectedErr
 	}
 
-	err := cmd.encryptAndSave([]byte("data"), reEncodeFunc)
+	err := cmd.encryptAndSave([]byte("data"), reEncodeFunc, domain.DefaultEncryptedRegex)
 
 	if err == nil {
 		t.Error("Expected error, got nil")
@@ -572,7 +671,7 @@ func TestEncryptAndSave_EncryptionError(t *testing.T) {
 		return b, nil
 	}
 
-	err := cmd.encryptAndSave([]byte("data"), reEncodeFunc)
+	err := cmd.encryptAndSave([]byte("data"), reEncodeFunc, domain.DefaultEncryptedRegex)
 
 	if err == nil {
 		t.Error("Expected error, got nil")
@@ -581,3 +680,52 @@ func TestEncryptAndSave_EncryptionError(t *testing.T) {
 		t.Errorf("Expected error to wrap %v, got %v", expectedErr, err)
 	}
 }
+
+func TestEdit_KeepsEncryptedRegexOfFile(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	es := encryption.NewSopsAgeDecryptStrategy()
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	plain := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n    name: app\ndata:\n    mode: prod\nconfig:\n    password: hunter2\n"
+	if err := os.WriteFile(path, []byte(plain), 0600); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := es.EncryptFile(path, "^(data|config)$", identity.Recipient().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, encrypted, 0600); err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := es.Decrypt(path, identity.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := SecretEditCmd{
+		keyManager:        &mockKeyManager{privateKey: identity.String()},
+		encryptionService: es,
+		editor:            &mockEditor{editedContent: []byte(strings.Replace(string(decrypted), "hunter2", "hunter3", 1))},
+		fileService:       &mockFileService{tempFilePath: "app.yaml"},
+		options: &editCmdOptions{
+			File:    path,
+			Cluster: "prod",
+		},
+	}
+	if _, _, err := cmd.edit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "encrypted_regex: ^(data|config)$") {
+		t.Errorf("Expected the encrypted regex of the file to be kept, got:\n%s", content)
+	}
+	if strings.Contains(string(content), "hunter3") {
+		t.Errorf("Expected the edited value to stay encrypted, got:\n%s", content)
+	}
+}
@@ -0,0 +1,21 @@
+package encrypt
+
+type SecretEncryptOptions struct {
+	Path           string
+	Cluster        string
+	InPlace        bool
+	Recursive      bool
+	EncryptedRegex string
+	AllowAnyKind   bool
+}
+
+func NewSecretEncryptOptions(path string, cluster string, inPlace bool, recursive bool, encryptedRegex string, allowAnyKind bool) *SecretEncryptOptions {
+	return &SecretEncryptOptions{
+		Path:           path,
+		Cluster:        cluster,
+		InPlace:        inPlace,
+		Recursive:      recursive,
+		EncryptedRegex: encryptedRegex,
+		AllowAnyKind:   allowAnyKind,
+	}
+}
@@ -0,0 +1,236 @@
+package encrypt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/audit"
+	"sopsctl/pkg/services/file"
+	"sopsctl/pkg/services/utils"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	inPlaceFlagName        = "in-place"
+	recursiveFlagName      = "recursive"
+	encryptedRegexFlagName = "encrypted-regex"
+	allowAnyKindFlagName   = "allow-any-kind"
+)
+
+var (
+	// errAlreadyEncrypted marks files that already carry sops metadata and are left untouched.
+	errAlreadyEncrypted = errors.New("already encrypted")
+	// errNotSecret marks manifests of other kinds than Secret, which are only encrypted
+	// with --allow-any-kind.
+	errNotSecret = errors.New("not a Secret")
+)
+
+type SecretEncryptCmd struct {
+	options           *SecretEncryptOptions
+	keyManager        domain.SopsKeyManager
+	encryptionService domain.EncryptionService
+	auditLog          domain.AuditLog
+}
+
+func NewSecretEncryptCmd(keyManager domain.SopsKeyManager, encryptionService domain.EncryptionService, auditLog domain.AuditLog) *SecretEncryptCmd {
+	return &SecretEncryptCmd{keyManager: keyManager, encryptionService: encryptionService, auditLog: auditLog}
+}
+
+func (e SecretEncryptCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().BoolP(inPlaceFlagName, "i", false, "Replace the plaintext files with their encrypted version instead of printing it")
+	cmd.Flags().BoolP(recursiveFlagName, "r", false, "Also encrypt the YAML files in subdirectories")
+	cmd.Flags().String(encryptedRegexFlagName, domain.DefaultEncryptedRegex, "Only encrypt the values whose keys match this regex")
+	cmd.Flags().Bool(allowAnyKindFlagName, false, "Encrypt YAML files that are not Secret manifests")
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+func (e SecretEncryptCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	gFlags, err := utils.UseGlobalFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("file does not exist: %s", path)
+	}
+	inPlace, err := cmd.Flags().GetBool(inPlaceFlagName)
+	if err != nil {
+		return nil, err
+	}
+	recursive, err := cmd.Flags().GetBool(recursiveFlagName)
+	if err != nil {
+		return nil, err
+	}
+	encryptedRegex, err := cmd.Flags().GetString(encryptedRegexFlagName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := regexp.Compile(encryptedRegex); err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", encryptedRegexFlagName, err)
+	}
+	allowAnyKind, err := cmd.Flags().GetBool(allowAnyKindFlagName)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() && !inPlace {
+		return nil, fmt.Errorf("encrypting a directory requires --%s", inPlaceFlagName)
+	}
+	e.options = NewSecretEncryptOptions(path, gFlags.Cluster, inPlace, recursive, encryptedRegex, allowAnyKind)
+	return e, nil
+}
+
+func (e SecretEncryptCmd) Execute() (string, error) {
+	publicKeys, err := e.keyManager.GetPublicKeys(e.options.Cluster)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(e.options.Path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return e.encryptSingleFile(publicKeys)
+	}
+
+	files, err := e.listYamlFiles()
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return color.YellowString("No YAML files found in %s", e.options.Path), nil
+	}
+	var output string
+	var encrypted, failed int
+	for _, path := range files {
+		_, err := e.encryptFile(path, publicKeys)
+		switch {
+		case errors.Is(err, errAlreadyEncrypted) || errors.Is(err, errNotSecret):
+			output += fmt.Sprintf("Skipped %s: %s\n", path, color.YellowString(err.Error()))
+		case err != nil:
+			failed++
+			output += fmt.Sprintf("Failed to encrypt %s: %s\n", path, color.RedString(err.Error()))
+		default:
+			encrypted++
+			output += fmt.Sprintf("Encrypted %s\n", color.GreenString(path))
+		}
+	}
+	summary := fmt.Sprintf("Encrypted %d of %d files", encrypted, len(files))
+	if failed > 0 {
+		// The listing is part of the error, the output of a failed command is not printed
+		return "", fmt.Errorf("%d of %d files failed to encrypt\n%s%s", failed, len(files), output, summary)
+	}
+	return output + summary, nil
+}
+
+func (e SecretEncryptCmd) encryptSingleFile(publicKeys []string) (string, error) {
+	encrypted, err := e.encryptFile(e.options.Path, publicKeys)
+	if errors.Is(err, errAlreadyEncrypted) {
+		return fmt.Sprintf("Skipped %s: %s", e.options.Path, err.Error()), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if e.options.InPlace {
+		return fmt.Sprintf("Encrypted %s", color.GreenString(e.options.Path)), nil
+	}
+	return string(encrypted), nil
+}
+
+// encryptFile encrypts a plaintext file and writes it back when encrypting in place.
+// Files that are already encrypted or not a Secret return errAlreadyEncrypted or errNotSecret.
+func (e SecretEncryptCmd) encryptFile(path string, publicKeys []string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	alreadyEncrypted, kinds, err := inspectManifests(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if alreadyEncrypted {
+		return nil, errAlreadyEncrypted
+	}
+	if !e.options.AllowAnyKind {
+		for _, kind := range kinds {
+			if kind != "Secret" {
+				return nil, fmt.Errorf("%w: kind is %q, use --%s to encrypt it anyway", errNotSecret, kind, allowAnyKindFlagName)
+			}
+		}
+	}
+
+	encrypted, err := e.encryptionService.EncryptFile(path, e.options.EncryptedRegex, publicKeys...)
+	if err == nil && e.options.InPlace {
+		err = file.AtomicWriteFile(path, encrypted)
+	}
+	e.auditLog.Record(domain.NewAuditEntry(domain.SecretEncrypt, e.options.Cluster, path, audit.DataKeys(data), err))
+	if err != nil {
+		return nil, err
+	}
+	return encrypted, nil
+}
+
+// listYamlFiles returns the sorted YAML files in the directory, including subdirectories
+// when encrypting recursively.
+func (e SecretEncryptCmd) listYamlFiles() ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(e.options.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != e.options.Path && !e.options.Recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// inspectManifests reports whether any document in the YAML already carries sops
+// metadata, and returns the kind of every document.
+func inspectManifests(data []byte) (bool, []string, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var kinds []string
+	for {
+		var doc map[string]interface{}
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return false, nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		if _, ok := doc["sops"]; ok {
+			return true, nil, nil
+		}
+		kind, _ := doc["kind"].(string)
+		kinds = append(kinds, kind)
+	}
+	return false, kinds, nil
+}
@@ -0,0 +1,109 @@
+package encrypt
+
+import (
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/encryption"
+	"sopsctl/pkg/services/testutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const plainSecret = `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+data:
+  password: aHVudGVyMg==
+`
+
+const plainConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  level: debug
+`
+
+func newTestEncryptCmd(t *testing.T, options *SecretEncryptOptions) *SecretEncryptCmd {
+	skm := testutil.NewKeyManager(t, "prod")
+	cmd := NewSecretEncryptCmd(skm, encryption.NewSopsAgeDecryptStrategy(), testutil.NopAuditLog{})
+	cmd.options = options
+	return cmd
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestSecretEncryptCmd_EncryptsDirectoryInPlace(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "db.yaml"), plainSecret)
+	writeFile(t, filepath.Join(dir, "settings.yaml"), plainConfigMap)
+	writeFile(t, filepath.Join(dir, "nested", "api.yml"), plainSecret)
+	uut := newTestEncryptCmd(t, NewSecretEncryptOptions(dir, "prod", true, true, domain.DefaultEncryptedRegex, false))
+
+	result, err := uut.Execute()
+	require.NoError(t, err)
+	assert.Contains(t, result, "Encrypted 2 of 3 files")
+
+	for _, name := range []string{"db.yaml", filepath.Join("nested", "api.yml")} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "ENC[AES256_GCM")
+		assert.NotContains(t, string(content), "aHVudGVyMg==")
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "settings.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, plainConfigMap, string(content))
+
+	// a second run leaves the already encrypted files alone
+	result, err = uut.Execute()
+	require.NoError(t, err)
+	assert.Contains(t, result, "Encrypted 0 of 3 files")
+}
+
+func TestSecretEncryptCmd_SkipsSubdirectoriesWithoutRecursive(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "nested", "api.yaml"), plainSecret)
+	uut := newTestEncryptCmd(t, NewSecretEncryptOptions(dir, "prod", true, false, domain.DefaultEncryptedRegex, false))
+
+	result, err := uut.Execute()
+	require.NoError(t, err)
+	assert.Contains(t, result, "No YAML files found")
+}
+
+func TestSecretEncryptCmd_RefusesOtherKinds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.yaml")
+	writeFile(t, path, plainConfigMap)
+	uut := newTestEncryptCmd(t, NewSecretEncryptOptions(path, "prod", false, false, domain.DefaultEncryptedRegex, false))
+
+	_, err := uut.Execute()
+	assert.ErrorIs(t, err, errNotSecret)
+
+	uut.options.AllowAnyKind = true
+	result, err := uut.Execute()
+	require.NoError(t, err)
+	assert.Contains(t, result, "ENC[AES256_GCM")
+}
+
+func TestSecretEncryptCmd_DirectoryFailsWhenAFileFails(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "broken.yaml"), "data: [unclosed\n")
+	writeFile(t, filepath.Join(dir, "db.yaml"), plainSecret)
+	uut := newTestEncryptCmd(t, NewSecretEncryptOptions(dir, "prod", true, false, domain.DefaultEncryptedRegex, false))
+
+	_, err := uut.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 files failed to encrypt")
+	assert.Contains(t, err.Error(), "broken.yaml")
+	assert.Contains(t, err.Error(), "Encrypted 1 of 2 files")
+
+	content, err := os.ReadFile(filepath.Join(dir, "db.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ENC[AES256_GCM")
+}
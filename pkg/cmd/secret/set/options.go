@@ -0,0 +1,15 @@
+package set
+
+type secretSetCmdOptions struct {
+	File          string
+	Cluster       string
+	RandomSources []string
+}
+
+func newSecretSetCmdOptions(file string, cluster string, randomSources []string) *secretSetCmdOptions {
+	return &secretSetCmdOptions{
+		File:          file,
+		Cluster:       cluster,
+		RandomSources: randomSources,
+	}
+}
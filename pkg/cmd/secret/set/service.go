@@ -0,0 +1,176 @@
+package set
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/file"
+	"sopsctl/pkg/services/generate"
+	"sopsctl/pkg/services/utils"
+	"sopsctl/pkg/services/yamlnode"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const fromRandomFlagName = "from-random"
+
+// SecretSetCmd sets values in an existing encrypted secret without opening an editor.
+type SecretSetCmd struct {
+	options           *secretSetCmdOptions
+	keyManager        domain.SopsKeyManager
+	encryptionService domain.EncryptionService
+	auditLog          domain.AuditLog
+}
+
+func NewSecretSetCmd(keyManager domain.SopsKeyManager, encryptionService domain.EncryptionService, auditLog domain.AuditLog) *SecretSetCmd {
+	return &SecretSetCmd{keyManager: keyManager, encryptionService: encryptionService, auditLog: auditLog}
+}
+
+func (s SecretSetCmd) InitCmd(cmd *cobra.Command) {
+	cmd.Flags().StringArray(fromRandomFlagName, nil, "Replace the value of a key with a random one (i.e. db-password=32:alnum, token=64:hex or secret=32:base64). The value is never printed.")
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+func (s SecretSetCmd) UseOptions(cmd *cobra.Command, args []string) (domain.CommandExecutor, error) {
+	filePath, err := utils.UserFileArg(args)
+	if err != nil {
+		return nil, err
+	}
+	randomSources, err := cmd.Flags().GetStringArray(fromRandomFlagName)
+	if err != nil {
+		return nil, err
+	}
+	if len(randomSources) == 0 {
+		return nil, fmt.Errorf("nothing to set, use --%s key=length[:charset]", fromRandomFlagName)
+	}
+	for _, source := range randomSources {
+		if _, _, err := generate.ParseRandomSource(source); err != nil {
+			return nil, err
+		}
+	}
+	cluster, err := utils.UseDecryptCluster(cmd, filePath, s.keyManager, s.encryptionService)
+	if err != nil {
+		return nil, err
+	}
+	s.options = newSecretSetCmdOptions(filePath, cluster, randomSources)
+	return s, nil
+}
+
+func (s SecretSetCmd) Execute() (string, error) {
+	result, keys, err := s.set()
+	s.auditLog.Record(domain.NewAuditEntry(domain.SecretSet, s.options.Cluster, s.options.File, keys, err))
+	return result, err
+}
+
+// set decrypts the file, replaces the values and writes the file back encrypted for the
+// same recipients and with the same encrypted regex. It returns the keys that were set.
+func (s SecretSetCmd) set() (string, []string, error) {
+	encryptedRegex, err := s.encryptionService.EncryptedRegex(s.options.File)
+	if err != nil {
+		return "", nil, err
+	}
+	privateKey, err := s.keyManager.GetPrivateKey(s.options.Cluster)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get private key for cluster %s: %w", s.options.Cluster, err)
+	}
+	decrypted, err := s.encryptionService.Decrypt(s.options.File, privateKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decrypt file %s: %w", s.options.File, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(decrypted, &doc); err != nil {
+		return "", nil, fmt.Errorf("failed to parse decrypted file: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || doc.Content[0].Kind != yaml.MappingNode {
+		return "", nil, fmt.Errorf("%s is not a Secret manifest", s.options.File)
+	}
+	root := doc.Content[0]
+	if kind := yamlnode.MappingValue(root, "kind"); kind == nil || kind.Value != "Secret" {
+		return "", nil, fmt.Errorf("%s is not a Secret manifest", s.options.File)
+	}
+
+	var keys []string
+	var output []string
+	for _, source := range s.options.RandomSources {
+		key, spec, err := generate.ParseRandomSource(source)
+		if err != nil {
+			return "", keys, err
+		}
+		if errs := validation.IsConfigMapKey(key); len(errs) != 0 {
+			return "", keys, fmt.Errorf("%q is not valid key name for a Secret %s", key, strings.Join(errs, ";"))
+		}
+		value, err := spec.Generate()
+		if err != nil {
+			return "", keys, err
+		}
+		setSecretValue(root, key, value)
+		keys = append(keys, key)
+		output = append(output, fmt.Sprintf("Set %s to a new random value (%s)", color.GreenString(key), spec))
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return "", keys, err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", keys, err
+	}
+
+	publicKeys, err := s.reEncryptionKeys()
+	if err != nil {
+		return "", keys, err
+	}
+	encrypted, err := s.encryptionService.EncryptDataWithRegex(buf.Bytes(), encryptedRegex, publicKeys...)
+	if err != nil {
+		return "", keys, fmt.Errorf("failed to re-encrypt file: %w", err)
+	}
+	if err := file.AtomicWriteFile(s.options.File, encrypted); err != nil {
+		return "", keys, fmt.Errorf("failed to atomically write encrypted data to %s: %w", s.options.File, err)
+	}
+	return strings.Join(output, "\n"), keys, nil
+}
+
+// reEncryptionKeys returns the age recipients the file is encrypted for, falling back to
+// the public key of the cluster it was decrypted with.
+func (s SecretSetCmd) reEncryptionKeys() ([]string, error) {
+	recipients, err := s.encryptionService.Recipients(s.options.File)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) > 0 {
+		return recipients, nil
+	}
+	publicKey, err := s.keyManager.GetPublicKey(s.options.Cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SOPS public key for cluster %s: %w", s.options.Cluster, err)
+	}
+	return []string{publicKey}, nil
+}
+
+// setSecretValue sets key in stringData when it is already kept there, or when the secret
+// only uses stringData, and base64 encoded in data otherwise.
+func setSecretValue(secret *yaml.Node, key string, value []byte) {
+	data := yamlnode.MappingValue(secret, "data")
+	stringData := yamlnode.MappingValue(secret, "stringData")
+	if stringData != nil && (yamlnode.MappingValue(stringData, key) != nil || data == nil) {
+		yamlnode.SetMappingValue(stringData, key, string(value))
+		return
+	}
+	if data == nil || data.Kind != yaml.MappingNode {
+		data = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		yamlnode.SetMappingNode(secret, "data", data)
+	}
+	if stringData != nil {
+		// a key must not be set in both, stringData would win when applied
+		yamlnode.RemoveMappingKey(stringData, key)
+	}
+	yamlnode.SetMappingValue(data, key, base64.StdEncoding.EncodeToString(value))
+}
@@ -0,0 +1,85 @@
+package set
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/encryption"
+	"sopsctl/pkg/services/testutil"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func setAndEncode(t *testing.T, manifest string, key string, value string) string {
+	var doc yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(manifest), &doc))
+	setSecretValue(doc.Content[0], key, []byte(value))
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	require.NoError(t, encoder.Encode(&doc))
+	return buf.String()
+}
+
+func TestSetSecretValue_EncodesIntoData(t *testing.T) {
+	result := setAndEncode(t, "kind: Secret\ndata:\n  user: YWRtaW4=\n", "password", "hunter2")
+	assert.Equal(t, "kind: Secret\ndata:\n  user: YWRtaW4=\n  password: aHVudGVyMg==\n", result)
+}
+
+func TestSetSecretValue_KeepsStringDataKeysThere(t *testing.T) {
+	result := setAndEncode(t, "kind: Secret\ndata:\n  user: YWRtaW4=\nstringData:\n  password: old\n", "password", "hunter2")
+	assert.Equal(t, "kind: Secret\ndata:\n  user: YWRtaW4=\nstringData:\n  password: hunter2\n", result)
+}
+
+func TestSetSecretValue_UsesStringDataWhenItIsTheOnlyMap(t *testing.T) {
+	result := setAndEncode(t, "kind: Secret\nstringData:\n  user: admin\n", "password", "hunter2")
+	assert.Equal(t, "kind: Secret\nstringData:\n  user: admin\n  password: hunter2\n", result)
+}
+
+func TestSetSecretValue_AddsData(t *testing.T) {
+	result := setAndEncode(t, "kind: Secret\nmetadata:\n  name: db\n", "password", "hunter2")
+	assert.Equal(t, "kind: Secret\nmetadata:\n  name: db\ndata:\n  password: aHVudGVyMg==\n", result)
+}
+
+func TestSecretSetCmd_KeepsRecipientsAndEncryptedRegexOfFile(t *testing.T) {
+	skm := testutil.NewKeyManager(t)
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, skm.ImportCtx("prod", domain.CTX{PrivateKey: identity.String(), Source: "private-key"}))
+
+	es := encryption.NewSopsAgeDecryptStrategy()
+	path := filepath.Join(t.TempDir(), "db.yaml")
+	plain := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  user: YWRtaW4=\nnote: rotate yearly\n"
+	require.NoError(t, os.WriteFile(path, []byte(plain), 0600))
+	recipients := []string{identity.Recipient().String(), other.Recipient().String()}
+	encrypted, err := es.EncryptFile(path, "^(data|note)$", recipients...)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, encrypted, 0600))
+
+	uut := NewSecretSetCmd(skm, es, testutil.NopAuditLog{})
+	uut.options = newSecretSetCmdOptions(path, "prod", []string{"password=32:alnum"})
+	_, err = uut.Execute()
+	require.NoError(t, err)
+
+	fileRecipients, err := es.Recipients(path)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, recipients, fileRecipients)
+	regex, err := es.EncryptedRegex(path)
+	require.NoError(t, err)
+	assert.Equal(t, "^(data|note)$", regex)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "rotate yearly")
+
+	decrypted, err := es.Decrypt(path, identity.String())
+	require.NoError(t, err)
+	assert.Contains(t, string(decrypted), "password:")
+	assert.Contains(t, string(decrypted), "note: rotate yearly")
+}
@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEntry is a single line in the audit log. It records which keys of a secret
+// were touched, never their values.
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Command   string    `json:"command"`
+	User      string    `json:"user,omitempty"`
+	Context   string    `json:"context,omitempty"`
+	File      string    `json:"file,omitempty"`
+	DataKeys  []string  `json:"dataKeys,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+	// HashChain is set on the entries that record hash chaining being turned on or off
+	HashChain *bool  `json:"hashChain,omitempty"`
+	PrevHash  string `json:"prevHash,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+}
+
+func NewAuditEntry(command CommandId, ctxName string, filePath string, dataKeys []string, err error) AuditEntry {
+	entry := AuditEntry{
+		Timestamp: time.Now().UTC(),
+		Command:   command.ToString(),
+		Context:   ctxName,
+		File:      filePath,
+		DataKeys:  dataKeys,
+		Outcome:   AuditOutcomeSuccess,
+	}
+	if err != nil {
+		entry.Outcome = AuditOutcomeFailure
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// NewHashChainAuditEntry records that hash chaining of the audit log was turned on or off.
+func NewHashChainAuditEntry(enabled bool, err error) AuditEntry {
+	entry := NewAuditEntry(AuditSettings, "", "", nil, err)
+	entry.HashChain = &enabled
+	return entry
+}
+
+type AuditFilter struct {
+	Command string
+	Context string
+	File    string
+	Outcome string
+	Since   time.Time
+}
+
+type AuditLog interface {
+	Record(entry AuditEntry)
+	Entries(filter AuditFilter) ([]AuditEntry, error)
+	Verify() error
+}
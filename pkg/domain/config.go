@@ -1,10 +1,62 @@
 package domain
 
+import "time"
+
 type CTX struct {
-	PrivateKey string
-	Namespace  string
-	SecretName string
-	KeyName    string
+	PrivateKey  string
+	PublicKey   string
+	Namespace   string
+	SecretName  string
+	KeyName     string
+	StorageMode string
+	// Source and AddedAt record where the stored key came from and when.
+	Source  string
+	AddedAt time.Time
+	// RefreshedAt is when key refresh last replaced the key with a rotated one.
+	RefreshedAt time.Time
+	// SharedBy is the user who handed over a key received with key receive.
+	SharedBy string
+	// Server and CAFingerprint identify the cluster the key was added for, independent
+	// of the kubeconfig context name.
+	Server        string
+	CAFingerprint string
+}
+
+// ClusterIdentity identifies a cluster by its API server URL and the SHA-256 fingerprint
+// of its CA certificate.
+type ClusterIdentity struct {
+	Server        string
+	CAFingerprint string
+}
+
+func (c ClusterIdentity) IsZero() bool {
+	return c.Server == "" && c.CAFingerprint == ""
+}
+
+// Matches reports whether both identities belong to the same cluster. The CA fingerprint
+// is compared when both are known, since the same cluster can be reached by different
+// server URLs.
+func (c ClusterIdentity) Matches(other ClusterIdentity) bool {
+	if c.CAFingerprint != "" && other.CAFingerprint != "" {
+		return c.CAFingerprint == other.CAFingerprint
+	}
+	return c.Server == other.Server
+}
+
+// ClusterIdentity returns the identity of the cluster the key was added for.
+func (c *CTX) ClusterIdentity() ClusterIdentity {
+	return ClusterIdentity{Server: c.Server, CAFingerprint: c.CAFingerprint}
+}
+
+// HasClusterReference reports whether the context records the cluster secret its key lives in.
+func (c *CTX) HasClusterReference() bool {
+	return c.SecretName != ""
+}
+
+// IsEncryptOnly reports whether the context only holds a public key, so secrets can be
+// encrypted for it but not decrypted.
+func (c *CTX) IsEncryptOnly() bool {
+	return c.PublicKey != "" && c.PrivateKey == "" && !c.HasClusterReference()
 }
 
 func NewReferenceCTX(namespace string, secretName string, keyName string) *CTX {
@@ -23,8 +75,16 @@ func NewEmptyCtx() *CTX {
 type ConfigStorage interface {
 	SaveStorageMode(mode string) error
 	GetStorageMode() (string, error)
+	SaveCtxStorageMode(ctxName string, mode string) error
+	GetCtxStorageMode(ctxName string) (string, error)
+	SaveAuditHashChain(enabled bool) error
+	SaveSopsKeyFallback(enabled bool) error
+	SaveClusterGroup(name string, members []string) error
+	SaveCtxClusterIdentity(ctxName string, identity ClusterIdentity) error
 	SaveConfigFile() error
 	SetPrivateKey(key string, ctxName string) error
+	SetPublicKey(key string, ctxName string) error
+	SetClusterKey(key string, ctxName string, namespace string, secretName string, keyName string, addedAt time.Time) error
 	GetPrivateKey(ctxName string) (string, error)
 	ListContextsWithKeys() ([]string, error)
 	RemoveCtx(ctxName string) error
@@ -7,13 +7,30 @@ func (receiver CommandId) ToString() string {
 }
 
 const (
-	SecretEdit     CommandId = "secret-edit"
-	SecretDecrypt  CommandId = "secret-decrypt"
-	SecretCreate   CommandId = "secret-create"
-	KeyAdd         CommandId = "key-add"
-	KeyList        CommandId = "key-list"
-	KeyRemove      CommandId = "key-remove"
-	KeyStorageMode CommandId = "key-storage-mode"
+	SecretEdit                 CommandId = "secret-edit"
+	SecretDecrypt              CommandId = "secret-decrypt"
+	SecretCreate               CommandId = "secret-create"
+	KeyAdd                     CommandId = "key-add"
+	KeyList                    CommandId = "key-list"
+	KeyRemove                  CommandId = "key-remove"
+	KeyStorageMode             CommandId = "key-storage-mode"
+	AuditSettings              CommandId = "audit-settings"
+	AuditShow                  CommandId = "audit-show"
+	KeyRefresh                 CommandId = "key-refresh"
+	KeyPrune                   CommandId = "key-prune"
+	KeyBackup                  CommandId = "key-backup"
+	KeyRestore                 CommandId = "key-restore"
+	KeyShare                   CommandId = "key-share"
+	KeyReceive                 CommandId = "key-receive"
+	KeySettings                CommandId = "key-settings"
+	KeyGroup                   CommandId = "key-group"
+	SecretEncrypt              CommandId = "secret-encrypt"
+	SecretCreateTLS            CommandId = "secret-create-tls"
+	SecretCreateDockerRegistry CommandId = "secret-create-docker-registry"
+	SecretSet                  CommandId = "secret-set"
+	SecretCreateFluxAuth       CommandId = "secret-create-flux-auth"
+	SecretCreateBasicAuth      CommandId = "secret-create-basic-auth"
+	SecretCreateHelmValues     CommandId = "secret-create-helm-values"
 )
 
 type StorageMode string
@@ -31,4 +48,32 @@ func (sm StorageMode) ToString() string {
 	return string(sm)
 }
 
+// KeySource records how a stored key was obtained.
+type KeySource string
+
+const (
+	ClusterKeySource   KeySource = "cluster"
+	PublicKeyKeySource KeySource = "public-key"
+	SharedKeySource    KeySource = "shared"
+)
+
+func (ks KeySource) ToString() string {
+	return string(ks)
+}
+
+// KeyRefreshStatus is the outcome of re-fetching a stored key from its recorded source.
+type KeyRefreshStatus string
+
+const (
+	KeyRefreshChanged     KeyRefreshStatus = "changed"
+	KeyRefreshUnchanged   KeyRefreshStatus = "unchanged"
+	KeyRefreshLive        KeyRefreshStatus = "live"
+	KeyRefreshNoSource    KeyRefreshStatus = "no-source"
+	KeyRefreshEncryptOnly KeyRefreshStatus = "encrypt-only"
+)
+
+// SopsAgeKeyCtxPrefix prefixes the pseudo context names of keys found in the standard
+// sops key locations, followed by the key's age recipient.
+const SopsAgeKeyCtxPrefix = "sops-age-key:"
+
 const EditorEnvName = "SOPSCTL_EDITOR"
@@ -2,10 +2,23 @@ package domain
 
 import "github.com/getsops/sops/v3/cmd/sops/formats"
 
+// DefaultEncryptedRegex selects the values of a Secret manifest that sops encrypts.
+const DefaultEncryptedRegex = "^(data|stringData)$"
+
 type EncryptionService interface {
 	Decrypt(filePath, ageKey string) ([]byte, error)
 	DecryptData(data []byte, ageKey string) ([]byte, error)
 	SopsDecryptWithFormat(data []byte, inputFormat, outputFormat formats.Format) (_ []byte, err error)
-	EncryptFile(filePath string, publicKey string) ([]byte, error)
-	EncryptData(data []byte, publicKey string) ([]byte, error)
+	// EncryptFile, EncryptData and EncryptDataWithRegex encrypt to every given age public
+	// key, any one of the matching private keys can decrypt the result. EncryptData
+	// encrypts the values matching DefaultEncryptedRegex, the others those matching
+	// encryptedRegex, or every value when it is empty.
+	EncryptFile(filePath string, encryptedRegex string, publicKeys ...string) ([]byte, error)
+	EncryptData(data []byte, publicKeys ...string) ([]byte, error)
+	EncryptDataWithRegex(data []byte, encryptedRegex string, publicKeys ...string) ([]byte, error)
+	// Recipients returns the age recipients an encrypted file can be decrypted for.
+	Recipients(filePath string) ([]string, error)
+	// EncryptedRegex returns the encrypted_regex an encrypted file was encrypted with,
+	// empty when every value of the file is encrypted.
+	EncryptedRegex(filePath string) (string, error)
 }
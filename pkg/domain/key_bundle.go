@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// KeyBundle is the plaintext content of an age-encrypted key backup or key share.
+// Contexts holds the stored contexts by name, including their private keys and provenance.
+type KeyBundle struct {
+	Version   int
+	CreatedAt time.Time
+	CreatedBy string
+	Contexts  map[string]CTX
+}
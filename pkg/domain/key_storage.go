@@ -4,9 +4,22 @@ type KeyStorage interface {
 	GetCtx(ctxName string) (*CTX, error)
 	SetStorageMode(mode StorageMode) error
 	GetStorageMode() (StorageMode, error)
+	SetCtxStorageMode(ctxName string, mode StorageMode) error
+	GetCtxStorageMode(ctxName string) (StorageMode, error)
+	SetAuditHashChain(enabled bool) error
+	GetAuditHashChain() (bool, error)
+	SetSopsKeyFallback(enabled bool) error
+	GetSopsKeyFallback() (bool, error)
+	SetClusterGroup(name string, members []string) error
+	GetClusterGroups() (map[string][]string, error)
 	SavePrivateKey(key string, ctxName string) error
+	SavePublicKey(key string, ctxName string) error
+	SaveClusterKey(key string, ctxName string, namespace string, secretName string, keyName string) error
+	RefreshClusterKey(key string, ctxName string) error
 	GetPrivateKey(ctxName string) (string, error)
 	ListContextsWithKeys() ([]string, error)
 	RemoveKeyForContext(ctx string) error
+	SaveCtx(ctxName string, ctx CTX) error
+	SetCtxClusterIdentity(ctxName string, identity ClusterIdentity) error
 	SaveCtxReference(ctxName string, namespace string, secretName string, key string) error
 }
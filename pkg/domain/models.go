@@ -5,5 +5,6 @@ type RawSecret struct {
 	Kind       string                 `json:"kind" yaml:"kind"`
 	Metadata   map[string]interface{} `json:"metadata" yaml:"metadata"`
 	Data       map[string]string      `json:"data" yaml:"data"`
+	StringData map[string]string      `json:"stringData,omitempty" yaml:"stringData,omitempty"`
 	Type       string                 `json:"type" yaml:"type"`
 }
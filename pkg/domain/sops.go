@@ -7,6 +7,16 @@ type SopsKeyManager interface {
 	GetPrivateKey(ctxName string) (string, error)
 	GetPublicKey(ctxName string) (string, error)
 	AddKeyFromCluster(ctxName string, namespace string, secretName string, secretKey string) (string, error)
+	AddPublicKey(ctxName string, publicKey string) (string, error)
+	IsEncryptOnly(ctxName string) (bool, error)
+	GetCtx(ctxName string) (*CTX, error)
+	RefreshKey(ctxName string) (KeyRefreshStatus, error)
+	ExportCtx(ctxName string) (*CTX, error)
+	FindCtxForRecipients(recipients []string, preferred string) (string, error)
+	ResolveClusters(cluster string) ([]string, error)
+	GetPublicKeys(cluster string) ([]string, error)
+	ImportCtx(ctxName string, ctx CTX) error
 	ListContextsWithKeys() ([]string, error)
 	RemoveKeyForContext(ctx string) error
+	GetStorageMode(ctxName string) (StorageMode, error)
 }
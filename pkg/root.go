@@ -2,15 +2,29 @@ package pkg
 
 import (
 	"fmt"
+	"os"
 	command "sopsctl/pkg/cmd"
+	"sopsctl/pkg/cmd/audit/settings"
+	"sopsctl/pkg/cmd/audit/show"
 	"sopsctl/pkg/cmd/key/add"
+	"sopsctl/pkg/cmd/key/backup"
+	"sopsctl/pkg/cmd/key/group"
 	"sopsctl/pkg/cmd/key/list"
+	"sopsctl/pkg/cmd/key/prune"
+	"sopsctl/pkg/cmd/key/receive"
+	"sopsctl/pkg/cmd/key/refresh"
 	"sopsctl/pkg/cmd/key/remove"
+	"sopsctl/pkg/cmd/key/restore"
+	keySettings "sopsctl/pkg/cmd/key/settings"
+	"sopsctl/pkg/cmd/key/share"
 	storageMode "sopsctl/pkg/cmd/key/storage"
 	"sopsctl/pkg/cmd/secret/create"
 	"sopsctl/pkg/cmd/secret/decrypt"
 	"sopsctl/pkg/cmd/secret/edit"
+	"sopsctl/pkg/cmd/secret/encrypt"
+	"sopsctl/pkg/cmd/secret/set"
 	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/audit"
 	"sopsctl/pkg/services/decoder"
 	"sopsctl/pkg/services/editor"
 	"sopsctl/pkg/services/encryption"
@@ -54,24 +68,87 @@ func GetDigServiceContainer() *dig.Container {
 		container.Provide(func() domain.FileService {
 			return file.NewFileService()
 		}),
+		container.Provide(func(ks domain.KeyStorage) domain.AuditLog {
+			return audit.NewJsonLinesAuditLog(ks)
+		}),
 
 		// Command builders
 		container.Provide(func(skm domain.SopsKeyManager) domain.CommandBuilder {
 			return list.NewKeyListCmd(skm)
 		}, dig.Name(domain.KeyList.ToString())),
 
-		container.Provide(func(skm domain.SopsKeyManager) domain.CommandBuilder {
-			return add.NewKeyAddCmd(skm)
+		container.Provide(func(skm domain.SopsKeyManager, auditLog domain.AuditLog) domain.CommandBuilder {
+			return add.NewKeyAddCmd(skm, auditLog)
 		}, dig.Name(domain.KeyAdd.ToString())),
 
-		container.Provide(func(skm domain.SopsKeyManager) domain.CommandBuilder {
-			return remove.NewKeyRemoveCmd(skm)
+		container.Provide(func(skm domain.SopsKeyManager, auditLog domain.AuditLog) domain.CommandBuilder {
+			return remove.NewKeyRemoveCmd(skm, auditLog)
 		}, dig.Name(domain.KeyRemove.ToString())),
 
-		container.Provide(func(skm domain.SopsKeyManager, es domain.EncryptionService) domain.CommandBuilder {
-			return create.NewSecretCreateCmd(es, skm)
+		container.Provide(func(skm domain.SopsKeyManager, auditLog domain.AuditLog) domain.CommandBuilder {
+			return refresh.NewKeyRefreshCmd(skm, auditLog)
+		}, dig.Name(domain.KeyRefresh.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, auditLog domain.AuditLog) domain.CommandBuilder {
+			return prune.NewKeyPruneCmd(skm, auditLog)
+		}, dig.Name(domain.KeyPrune.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, auditLog domain.AuditLog) domain.CommandBuilder {
+			return backup.NewKeyBackupCmd(skm, auditLog)
+		}, dig.Name(domain.KeyBackup.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, auditLog domain.AuditLog) domain.CommandBuilder {
+			return restore.NewKeyRestoreCmd(skm, auditLog)
+		}, dig.Name(domain.KeyRestore.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, auditLog domain.AuditLog) domain.CommandBuilder {
+			return share.NewKeyShareCmd(skm, auditLog)
+		}, dig.Name(domain.KeyShare.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, auditLog domain.AuditLog) domain.CommandBuilder {
+			return receive.NewKeyReceiveCmd(skm, auditLog)
+		}, dig.Name(domain.KeyReceive.ToString())),
+
+		container.Provide(func(ks domain.KeyStorage) domain.CommandBuilder {
+			return keySettings.NewKeySettingsCmd(ks)
+		}, dig.Name(domain.KeySettings.ToString())),
+
+		container.Provide(func(ks domain.KeyStorage) domain.CommandBuilder {
+			return group.NewKeyGroupCmd(ks)
+		}, dig.Name(domain.KeyGroup.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, es domain.EncryptionService, auditLog domain.AuditLog) domain.CommandBuilder {
+			return create.NewSecretCreateCmd(es, skm, auditLog)
 		}, dig.Name(domain.SecretCreate.ToString())),
 
+		container.Provide(func(skm domain.SopsKeyManager, es domain.EncryptionService, auditLog domain.AuditLog) domain.CommandBuilder {
+			return create.NewSecretCreateTLSCmd(es, skm, auditLog)
+		}, dig.Name(domain.SecretCreateTLS.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, es domain.EncryptionService, auditLog domain.AuditLog) domain.CommandBuilder {
+			return create.NewSecretCreateDockerRegistryCmd(es, skm, auditLog)
+		}, dig.Name(domain.SecretCreateDockerRegistry.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, es domain.EncryptionService, auditLog domain.AuditLog) domain.CommandBuilder {
+			return create.NewSecretCreateFluxAuthCmd(es, skm, auditLog)
+		}, dig.Name(domain.SecretCreateFluxAuth.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, es domain.EncryptionService, auditLog domain.AuditLog) domain.CommandBuilder {
+			return create.NewSecretCreateBasicAuthCmd(es, skm, auditLog)
+		}, dig.Name(domain.SecretCreateBasicAuth.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, es domain.EncryptionService, auditLog domain.AuditLog) domain.CommandBuilder {
+			return create.NewSecretCreateHelmValuesCmd(es, skm, auditLog)
+		}, dig.Name(domain.SecretCreateHelmValues.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, es domain.EncryptionService, auditLog domain.AuditLog) domain.CommandBuilder {
+			return set.NewSecretSetCmd(skm, es, auditLog)
+		}, dig.Name(domain.SecretSet.ToString())),
+
+		container.Provide(func(skm domain.SopsKeyManager, es domain.EncryptionService, auditLog domain.AuditLog) domain.CommandBuilder {
+			return encrypt.NewSecretEncryptCmd(skm, es, auditLog)
+		}, dig.Name(domain.SecretEncrypt.ToString())),
+
 		container.Provide(func(skm domain.KeyStorage) domain.CommandBuilder {
 			return storageMode.NewKeyStorageModeCmd(skm)
 		}, dig.Name(domain.KeyStorageMode.ToString())),
@@ -79,8 +156,9 @@ func GetDigServiceContainer() *dig.Container {
 		container.Provide(func(
 			skm domain.SopsKeyManager,
 			encService domain.EncryptionService,
+			auditLog domain.AuditLog,
 		) domain.CommandBuilder {
-			return decrypt.NewSecretDecryptCmd(skm, encService)
+			return decrypt.NewSecretDecryptCmd(skm, encService, auditLog)
 		}, dig.Name(domain.SecretDecrypt.ToString())),
 
 		container.Provide(func(
@@ -89,10 +167,19 @@ func GetDigServiceContainer() *dig.Container {
 			b64Decoder domain.Base64Decoder,
 			editorService domain.UserEditorService,
 			fileService domain.FileService,
+			auditLog domain.AuditLog,
 		) domain.CommandBuilder {
-			return edit.NewSecretEditCmd(skm, encService, b64Decoder, editorService, fileService)
+			return edit.NewSecretEditCmd(skm, encService, b64Decoder, editorService, fileService, auditLog)
 		}, dig.Name(domain.SecretEdit.ToString())),
 
+		container.Provide(func(ks domain.KeyStorage, auditLog domain.AuditLog) domain.CommandBuilder {
+			return settings.NewAuditSettingsCmd(ks, auditLog)
+		}, dig.Name(domain.AuditSettings.ToString())),
+
+		container.Provide(func(auditLog domain.AuditLog) domain.CommandBuilder {
+			return show.NewAuditShowCmd(auditLog)
+		}, dig.Name(domain.AuditShow.ToString())),
+
 		// CommandFactory
 		container.Provide(func(params command.CommandFactoryParams) domain.CommandFactory {
 			return command.NewCommandFactory(params)
@@ -121,20 +208,30 @@ func InitCobraCommand(commandId domain.CommandId, cmd *cobra.Command) {
 	builder.InitCmd(cmd)
 }
 
+// ExecuteCobraCommand runs the command and exits with status 1 when it fails, so scripts
+// and CI can rely on the exit code.
 func ExecuteCobraCommand(commandId domain.CommandId, cmd *cobra.Command, args []string) {
-	executor, err := GetCommandBuilder(commandId).UseOptions(cmd, args)
+	if err := runCommand(GetCommandBuilder(commandId), cmd, args); err != nil {
+		os.Exit(1)
+	}
+}
+
+// runCommand prints the result of the command, or the error it failed with.
+func runCommand(builder domain.CommandBuilder, cmd *cobra.Command, args []string) error {
+	executor, err := builder.UseOptions(cmd, args)
 	if err != nil {
 		helpers.PrintError("failed", err)
 
 		helpText := "To get help, run:\n\n"
 		helpText += fmt.Sprintf("  %s --help\n", cmd.CommandPath())
 		fmt.Println(helpText)
-		return
+		return err
 	}
 	result, err := executor.Execute()
 	if err != nil {
-		helpers.PrintError("Failed to execute command: %v", err)
-		return
+		helpers.PrintError("Failed to execute command", err)
+		return err
 	}
 	fmt.Println(result)
+	return nil
 }
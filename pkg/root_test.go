@@ -0,0 +1,34 @@
+package pkg
+
+import (
+	"errors"
+	"sopsctl/pkg/domain"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCommand struct {
+	optionsErr error
+	executeErr error
+}
+
+func (f fakeCommand) InitCmd(*cobra.Command) {}
+
+func (f fakeCommand) UseOptions(*cobra.Command, []string) (domain.CommandExecutor, error) {
+	return f, f.optionsErr
+}
+
+func (f fakeCommand) Execute() (string, error) {
+	return "done", f.executeErr
+}
+
+func TestRunCommand_ReturnsTheErrorSoTheCommandExitsWithStatus1(t *testing.T) {
+	invalid := errors.New("invalid flag")
+	failed := errors.New("decrypt failed")
+
+	assert.NoError(t, runCommand(fakeCommand{}, &cobra.Command{}, nil))
+	assert.ErrorIs(t, runCommand(fakeCommand{optionsErr: invalid}, &cobra.Command{}, nil), invalid)
+	assert.ErrorIs(t, runCommand(fakeCommand{executeErr: failed}, &cobra.Command{}, nil), failed)
+}
@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"sopsctl/pkg/domain"
+	"sort"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DataKeys returns the sorted data and stringData keys of a plaintext secret manifest.
+// Manifests that cannot be parsed yield no keys.
+func DataKeys(manifest []byte) []string {
+	secret := &domain.RawSecret{}
+	if err := yaml.Unmarshal(manifest, secret); err != nil {
+		return nil
+	}
+	var keys []string
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	for key := range secret.StringData {
+		if _, exists := secret.Data[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ChangedDataKeys returns the sorted keys that were added, removed or changed
+// between two plaintext secret manifests.
+func ChangedDataKeys(before []byte, after []byte) []string {
+	beforeValues := dataValues(before)
+	afterValues := dataValues(after)
+	var keys []string
+	for key, value := range beforeValues {
+		if afterValue, exists := afterValues[key]; !exists || afterValue != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range afterValues {
+		if _, exists := beforeValues[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func dataValues(manifest []byte) map[string]string {
+	values := map[string]string{}
+	secret := &domain.RawSecret{}
+	if err := yaml.Unmarshal(manifest, secret); err != nil {
+		return values
+	}
+	for key, value := range secret.StringData {
+		values[key] = value
+	}
+	for key, value := range secret.Data {
+		values[key] = value
+	}
+	return values
+}
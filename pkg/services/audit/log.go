@@ -0,0 +1,214 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/file"
+	"sopsctl/pkg/services/helpers"
+
+	"k8s.io/client-go/util/homedir"
+)
+
+const logFileName = "audit.log"
+
+// JsonLinesAuditLog appends audit entries as JSON lines to ~/.sopsctl/audit.log.
+// When hash chaining is enabled every entry carries the hash of the line before it,
+// so removed or altered entries can be detected with Verify.
+type JsonLinesAuditLog struct {
+	storage  domain.KeyStorage
+	filePath string
+}
+
+func NewJsonLinesAuditLog(storage domain.KeyStorage) *JsonLinesAuditLog {
+	filePath := ""
+	if hd := homedir.HomeDir(); hd != "" {
+		filePath = filepath.Join(hd, ".sopsctl", logFileName)
+	}
+	return &JsonLinesAuditLog{storage: storage, filePath: filePath}
+}
+
+// Record appends the entry to the audit log. Failing to write the log does not fail
+// the audited command, it is reported as an error on the terminal instead.
+func (a *JsonLinesAuditLog) Record(entry domain.AuditEntry) {
+	if err := a.append(entry); err != nil {
+		helpers.PrintError("failed to write audit log", err)
+	}
+}
+
+func (a *JsonLinesAuditLog) append(entry domain.AuditEntry) error {
+	if a.filePath == "" {
+		return fmt.Errorf("could not determine home directory for the audit log")
+	}
+	if entry.User == "" {
+		entry.User = helpers.CurrentUserName()
+	}
+	if err := os.MkdirAll(filepath.Dir(a.filePath), 0700); err != nil {
+		return fmt.Errorf("create audit log directory: %w", err)
+	}
+	lock, err := file.AcquireLock(a.filePath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+
+	hashChain, err := a.storage.GetAuditHashChain()
+	if err != nil {
+		return err
+	}
+	if hashChain {
+		entries, err := a.readAll()
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			entry.PrevHash = entries[len(entries)-1].Hash
+		}
+		entry.Hash, err = hashEntry(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(a.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Entries returns the entries matching the filter in the order they were recorded.
+func (a *JsonLinesAuditLog) Entries(filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	entries, err := a.readAll()
+	if err != nil {
+		return nil, err
+	}
+	var result []domain.AuditEntry
+	for _, entry := range entries {
+		if matches(entry, filter) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// Verify checks the hash of every chained entry and that it links to the line before it.
+// Once an entry is chained every later entry must be too, until a chained entry records
+// that chaining was turned off, so hashes cannot simply be dropped from the tail.
+func (a *JsonLinesAuditLog) Verify() error {
+	entries, err := a.readAll()
+	if err != nil {
+		return err
+	}
+	hashChain, err := a.storage.GetAuditHashChain()
+	if err != nil {
+		return err
+	}
+	prevHash := ""
+	chained := false
+	for i, entry := range entries {
+		if entry.Hash == "" && chained {
+			return fmt.Errorf("audit log entry %d is not chained although hash chaining was enabled, its hash was removed", i+1)
+		}
+		if entry.Hash != "" {
+			if entry.PrevHash != prevHash {
+				return fmt.Errorf("audit log entry %d does not link to the entry before it, entries were removed or reordered", i+1)
+			}
+			expected, err := hashEntry(entry)
+			if err != nil {
+				return err
+			}
+			if expected != entry.Hash {
+				return fmt.Errorf("audit log entry %d was modified after it was recorded", i+1)
+			}
+			chained = entry.HashChain == nil || *entry.HashChain
+		}
+		prevHash = entry.Hash
+	}
+	if hashChain && len(entries) > 0 && entries[len(entries)-1].Hash == "" {
+		return fmt.Errorf("hash chaining is enabled but the last audit log entry is not chained, its hash was removed")
+	}
+	return nil
+}
+
+func (a *JsonLinesAuditLog) readAll() ([]domain.AuditEntry, error) {
+	if a.filePath == "" {
+		return nil, fmt.Errorf("could not determine home directory for the audit log")
+	}
+	f, err := os.Open(a.filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var entries []domain.AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry domain.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("audit log line %d is not valid JSON: %w", lineNumber, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+func matches(entry domain.AuditEntry, filter domain.AuditFilter) bool {
+	if filter.Command != "" && entry.Command != filter.Command {
+		return false
+	}
+	if filter.Context != "" && entry.Context != filter.Context {
+		return false
+	}
+	if filter.File != "" && entry.File != filter.File {
+		return false
+	}
+	if filter.Outcome != "" && entry.Outcome != filter.Outcome {
+		return false
+	}
+	if !filter.Since.IsZero() && entry.Timestamp.Before(filter.Since) {
+		return false
+	}
+	return true
+}
+
+// hashEntry hashes the JSON encoding of the entry without its own hash. The previous
+// hash is part of the encoding, which is what links the entries into a chain.
+func hashEntry(entry domain.AuditEntry) (string, error) {
+	entry.Hash = ""
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuditLog(t *testing.T, hashChain bool) *JsonLinesAuditLog {
+	t.Setenv("HOME", t.TempDir())
+	ks := storage.NewLocalUserKeyStorageService()
+	require.NoError(t, ks.SetAuditHashChain(hashChain))
+	return NewJsonLinesAuditLog(ks)
+}
+
+func TestJsonLinesAuditLog_Record_Entries(t *testing.T) {
+	uut := newTestAuditLog(t, false)
+
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/secret.yaml", []string{"password"}, nil))
+	uut.Record(domain.NewAuditEntry(domain.SecretEdit, "dev", "/tmp/other.yaml", nil, errors.New("boom")))
+
+	entries, err := uut.Entries(domain.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "secret-decrypt", entries[0].Command)
+	assert.Equal(t, []string{"password"}, entries[0].DataKeys)
+	assert.Equal(t, domain.AuditOutcomeSuccess, entries[0].Outcome)
+	assert.Equal(t, domain.AuditOutcomeFailure, entries[1].Outcome)
+	assert.Equal(t, "boom", entries[1].Error)
+	assert.Empty(t, entries[0].Hash)
+
+	info, err := os.Stat(uut.filePath)
+	require.NoError(t, err)
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("expected permissions 0600, got %o", info.Mode().Perm())
+	}
+}
+
+func TestJsonLinesAuditLog_Entries_Filter(t *testing.T) {
+	uut := newTestAuditLog(t, false)
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/a.yaml", nil, nil))
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "dev", "/tmp/a.yaml", nil, nil))
+	uut.Record(domain.NewAuditEntry(domain.KeyRemove, "prod", "", nil, errors.New("boom")))
+
+	byContext, err := uut.Entries(domain.AuditFilter{Context: "prod"})
+	require.NoError(t, err)
+	assert.Len(t, byContext, 2)
+
+	byCommandAndOutcome, err := uut.Entries(domain.AuditFilter{Command: "key-remove", Outcome: domain.AuditOutcomeFailure})
+	require.NoError(t, err)
+	assert.Len(t, byCommandAndOutcome, 1)
+
+	inFuture, err := uut.Entries(domain.AuditFilter{Since: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, inFuture)
+}
+
+func TestJsonLinesAuditLog_HashChain_Verify(t *testing.T) {
+	uut := newTestAuditLog(t, true)
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/a.yaml", []string{"a"}, nil))
+	uut.Record(domain.NewAuditEntry(domain.SecretEdit, "prod", "/tmp/a.yaml", []string{"a"}, nil))
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/a.yaml", []string{"a"}, nil))
+
+	entries, err := uut.Entries(domain.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.NotEmpty(t, entries[0].Hash)
+	assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+	assert.NoError(t, uut.Verify())
+}
+
+func TestJsonLinesAuditLog_HashChain_DetectsRemovedEntry(t *testing.T) {
+	uut := newTestAuditLog(t, true)
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/a.yaml", nil, nil))
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/b.yaml", nil, nil))
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/c.yaml", nil, nil))
+
+	content, err := os.ReadFile(uut.filePath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	tampered := strings.Join([]string{lines[0], lines[2]}, "\n") + "\n"
+	require.NoError(t, os.WriteFile(uut.filePath, []byte(tampered), 0600))
+
+	assert.Error(t, uut.Verify())
+}
+
+func TestJsonLinesAuditLog_HashChain_DetectsModifiedEntry(t *testing.T) {
+	uut := newTestAuditLog(t, true)
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/a.yaml", nil, nil))
+
+	content, err := os.ReadFile(uut.filePath)
+	require.NoError(t, err)
+	tampered := strings.Replace(string(content), `"context":"prod"`, `"context":"dev"`, 1)
+	require.NoError(t, os.WriteFile(uut.filePath, []byte(tampered), 0600))
+
+	assert.Error(t, uut.Verify())
+}
+
+func TestJsonLinesAuditLog_HashChain_DetectsStrippedHashes(t *testing.T) {
+	uut := newTestAuditLog(t, true)
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/a.yaml", nil, nil))
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/b.yaml", nil, nil))
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/c.yaml", nil, nil))
+
+	entries, err := uut.Entries(domain.AuditFilter{})
+	require.NoError(t, err)
+	stripTail := func(from int) {
+		var lines []string
+		for i, entry := range entries {
+			if i >= from {
+				entry.Hash, entry.PrevHash = "", ""
+			}
+			line, err := json.Marshal(entry)
+			require.NoError(t, err)
+			lines = append(lines, string(line))
+		}
+		require.NoError(t, os.WriteFile(uut.filePath, []byte(strings.Join(lines, "\n")+"\n"), 0600))
+	}
+
+	stripTail(1)
+	assert.ErrorContains(t, uut.Verify(), "entry 2 is not chained")
+
+	stripTail(0)
+	assert.ErrorContains(t, uut.Verify(), "last audit log entry is not chained")
+}
+
+func TestJsonLinesAuditLog_HashChain_DisabledOnPurpose(t *testing.T) {
+	uut := newTestAuditLog(t, true)
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/a.yaml", nil, nil))
+	uut.Record(domain.NewHashChainAuditEntry(false, nil))
+	require.NoError(t, uut.storage.SetAuditHashChain(false))
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/b.yaml", nil, nil))
+	assert.NoError(t, uut.Verify())
+
+	require.NoError(t, uut.storage.SetAuditHashChain(true))
+	uut.Record(domain.NewHashChainAuditEntry(true, nil))
+	uut.Record(domain.NewAuditEntry(domain.SecretDecrypt, "prod", "/tmp/c.yaml", nil, nil))
+	assert.NoError(t, uut.Verify())
+
+	entries, err := uut.Entries(domain.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+	assert.NotEmpty(t, entries[1].Hash)
+	assert.Empty(t, entries[2].Hash)
+	assert.NotEmpty(t, entries[3].Hash)
+}
+
+func TestChangedDataKeys(t *testing.T) {
+	before := []byte(`apiVersion: v1
+kind: Secret
+data:
+  same: YQ==
+  changed: Yg==
+  removed: Yw==
+`)
+	after := []byte(`apiVersion: v1
+kind: Secret
+data:
+  same: YQ==
+  changed: Ynl5
+stringData:
+  added: plain
+`)
+
+	assert.Equal(t, []string{"added", "changed", "removed"}, ChangedDataKeys(before, after))
+	assert.Equal(t, []string{"changed", "removed", "same"}, DataKeys(before))
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/getsops/sops/v3/cmd/sops/formats"
 	"github.com/getsops/sops/v3/config"
 	"github.com/getsops/sops/v3/decrypt"
-	"github.com/getsops/sops/v3/keys"
 	"github.com/getsops/sops/v3/keyservice"
 
 	"os"
@@ -23,23 +22,36 @@ type SopsAgeDecryptStrategy struct {
 	checkSopsMac bool
 }
 
-func (s *SopsAgeDecryptStrategy) EncryptData(data []byte, publicKey string) ([]byte, error) {
+func (s *SopsAgeDecryptStrategy) EncryptData(data []byte, publicKeys ...string) ([]byte, error) {
+	return s.encryptData(data, domain.DefaultEncryptedRegex, publicKeys)
+}
+
+func (s *SopsAgeDecryptStrategy) EncryptDataWithRegex(data []byte, encryptedRegex string, publicKeys ...string) ([]byte, error) {
+	return s.encryptData(data, encryptedRegex, publicKeys)
+}
+
+func (s *SopsAgeDecryptStrategy) encryptData(data []byte, encryptedRegex string, publicKeys []string) ([]byte, error) {
 	store := common.StoreForFormat(formats.Yaml, config.NewStoresConfig())
 	branches, err := store.LoadPlainFile(data)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
-	masterKey, err := keysource.MasterKeyFromRecipient(publicKey)
-	if err != nil {
-		panic(err)
+	if len(publicKeys) == 0 {
+		return nil, fmt.Errorf("no age public key to encrypt to")
+	}
+	var keyGroup sops.KeyGroup
+	for _, publicKey := range publicKeys {
+		masterKey, err := keysource.MasterKeyFromRecipient(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age public key %q: %w", publicKey, err)
+		}
+		keyGroup = append(keyGroup, masterKey)
 	}
 	tree := sops.Tree{
 		Branches: branches,
 		Metadata: sops.Metadata{
-			KeyGroups: []sops.KeyGroup{
-				[]keys.MasterKey{masterKey},
-			},
-			EncryptedRegex: "^(data|stringData)$",
+			KeyGroups:      []sops.KeyGroup{keyGroup},
+			EncryptedRegex: encryptedRegex,
 		},
 	}
 
@@ -163,10 +175,50 @@ func (s *SopsAgeDecryptStrategy) SopsDecryptWithFormat(data []byte, inputFormat,
 	return out, err
 }
 
-func (s *SopsAgeDecryptStrategy) EncryptFile(filePath string, publicKey string) ([]byte, error) {
+func (s *SopsAgeDecryptStrategy) Recipients(filePath string) ([]string, error) {
+	metadata, err := loadMetadata(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var recipients []string
+	for _, group := range metadata.KeyGroups {
+		for _, masterKey := range group {
+			if ageKey, ok := masterKey.(*keysource.MasterKey); ok {
+				recipients = append(recipients, ageKey.Recipient)
+			}
+		}
+	}
+	return recipients, nil
+}
+
+func (s *SopsAgeDecryptStrategy) EncryptedRegex(filePath string) (string, error) {
+	metadata, err := loadMetadata(filePath)
+	if err != nil {
+		return "", err
+	}
+	// Files encrypted with other rules, such as an unencrypted suffix, give an empty regex,
+	// so they are encrypted completely rather than partly in plain text
+	return metadata.EncryptedRegex, nil
+}
+
+// loadMetadata reads the sops metadata of an encrypted YAML file.
+func loadMetadata(filePath string) (*sops.Metadata, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	store := common.StoreForFormat(formats.Yaml, config.NewStoresConfig())
+	tree, err := store.LoadEncryptedFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sops metadata of %s: %w", filePath, err)
+	}
+	return &tree.Metadata, nil
+}
+
+func (s *SopsAgeDecryptStrategy) EncryptFile(filePath string, encryptedRegex string, publicKeys ...string) ([]byte, error) {
 	file, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
 	}
-	return s.EncryptData(file, publicKey)
+	return s.encryptData(file, encryptedRegex, publicKeys)
 }
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"unicode"
+
+	"filippo.io/age"
 )
 
 func TestNewSopsAgeDecryptStrategy(t *testing.T) {
@@ -70,3 +72,55 @@ func removeWhitespace(file string) string {
 		return r
 	}, file)
 }
+
+func TestSopsAgeDecryptStrategy_Recipients(t *testing.T) {
+	strategy := NewSopsAgeDecryptStrategy()
+
+	recipients, err := strategy.Recipients("./testdata/enc.yaml")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recipients) != 1 || recipients[0] != "age1qnswq576pku84s2wyw4kr59ywvvdzua6crtdz0sf0l9udnje6c5snqfc2d" {
+		t.Errorf("unexpected recipients: %v", recipients)
+	}
+}
+
+func TestSopsAgeDecryptStrategy_Recipients_NotEncrypted(t *testing.T) {
+	strategy := NewSopsAgeDecryptStrategy()
+
+	_, err := strategy.Recipients("./testdata/dec.yaml")
+
+	if err == nil {
+		t.Error("expected error for a file without sops metadata")
+	}
+}
+
+func TestSopsAgeDecryptStrategy_EncryptData_MultipleRecipients(t *testing.T) {
+	strategy := NewSopsAgeDecryptStrategy()
+	var privateKeys, publicKeys []string
+	for i := 0; i < 2; i++ {
+		identity, err := age.GenerateX25519Identity()
+		if err != nil {
+			t.Fatal(err)
+		}
+		privateKeys = append(privateKeys, identity.String())
+		publicKeys = append(publicKeys, identity.Recipient().String())
+	}
+	plain, _ := os.ReadFile("./testdata/dec.yaml")
+
+	encrypted, err := strategy.EncryptData(plain, publicKeys...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, privateKey := range privateKeys {
+		decrypted, err := strategy.DecryptData(encrypted, privateKey)
+		if err != nil {
+			t.Fatalf("every recipient should be able to decrypt: %v", err)
+		}
+		if removeWhitespace(string(decrypted)) != removeWhitespace(string(plain)) {
+			t.Errorf("decrypted data does not match expected cleartext")
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package file
+
+import (
+	"fmt"
+	"os"
+)
+
+// Lock is an exclusive advisory lock held on a lock file, so concurrent sopsctl
+// invocations cannot interleave their read-modify-write cycles on the guarded file.
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock blocks until the lock on path is held, creating the lock file if needed.
+func AcquireLock(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("open lock file %s: %w", path, err)
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("lock %s: %w", path, err)
+	}
+	return &Lock{file: f}, nil
+}
+
+func (l *Lock) Release() error {
+	err := unlockFile(l.file)
+	closeErr := l.file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
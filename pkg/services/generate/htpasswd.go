@@ -0,0 +1,58 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxHtpasswdPasswordLength is the number of bytes of a password bcrypt can hash
+const MaxHtpasswdPasswordLength = 72
+
+// ValidateHtpasswdUser checks that user can be written to an htpasswd file.
+func ValidateHtpasswdUser(user string) error {
+	if user == "" {
+		return fmt.Errorf("user name must not be empty")
+	}
+	if strings.ContainsAny(user, ": \t\r\n") {
+		return fmt.Errorf("user name %q must not contain a colon or whitespace", user)
+	}
+	return nil
+}
+
+// ValidateHtpasswdPassword checks that bcrypt can hash the password of user.
+func ValidateHtpasswdPassword(user string, password []byte) error {
+	if len(password) > MaxHtpasswdPasswordLength {
+		return fmt.Errorf("the password of %s is %d bytes long, bcrypt only hashes up to %d bytes", user, len(password), MaxHtpasswdPasswordLength)
+	}
+	return nil
+}
+
+// Htpasswd returns an htpasswd file with a bcrypt hashed entry for each user, in the
+// given order. Every user needs a password.
+func Htpasswd(users []string, passwords map[string][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, user := range users {
+		if err := ValidateHtpasswdUser(user); err != nil {
+			return nil, err
+		}
+		password, found := passwords[user]
+		if !found || len(password) == 0 {
+			return nil, fmt.Errorf("no password for user %s", user)
+		}
+		if err := ValidateHtpasswdPassword(user, password); err != nil {
+			return nil, err
+		}
+		hash, err := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("cannot hash the password of %s: %w", user, err)
+		}
+		buf.WriteString(user)
+		buf.WriteByte(':')
+		buf.Write(hash)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
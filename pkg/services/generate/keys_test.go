@@ -0,0 +1,77 @@
+package generate
+
+import (
+	"crypto/ecdh"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHKeyPair(t *testing.T) {
+	for _, keyType := range []string{Ed25519KeyType, EcdsaKeyType} {
+		privateKey, publicKey, err := SSHKeyPair(keyType, "deploy")
+		require.NoError(t, err)
+
+		signer, err := ssh.ParsePrivateKey(privateKey)
+		require.NoError(t, err)
+		parsed, comment, _, _, err := ssh.ParseAuthorizedKey(publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, "deploy", comment)
+		assert.Equal(t, signer.PublicKey().Marshal(), parsed.Marshal())
+	}
+}
+
+func TestWireGuardKeyPair(t *testing.T) {
+	privateKey, publicKey, err := WireGuardKeyPair()
+	require.NoError(t, err)
+
+	raw, err := base64.StdEncoding.DecodeString(string(privateKey))
+	require.NoError(t, err)
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), string(publicKey))
+}
+
+func TestParseCertificateSpec(t *testing.T) {
+	spec, err := ParseCertificateSpec("cn=web.example.com,days=30,dns=web.example.com;10.0.0.1,ca=ca.enc.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, CertificateSpec{
+		CommonName: "web.example.com",
+		Days:       30,
+		DNSNames:   []string{"web.example.com", "10.0.0.1"},
+		CARef:      "ca.enc.yaml",
+	}, spec)
+
+	for _, invalid := range []string{"days=30", "cn=x,days=0", "cn=x,size=2048", "cn=x,is-ca,ca=ca.yaml"} {
+		_, err := ParseCertificateSpec(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestCertificate_SignedByGeneratedCA(t *testing.T) {
+	caCert, caKey, err := Certificate(CertificateSpec{CommonName: "my-ca", Days: 30, IsCA: true}, nil, nil)
+	require.NoError(t, err)
+	cert, _, err := Certificate(CertificateSpec{CommonName: "web.example.com", Days: 30}, caCert, caKey)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caCert))
+	block, _ := pem.Decode(cert)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: roots, DNSName: "web.example.com"})
+	assert.NoError(t, err)
+}
+
+func TestCertificate_RefusesNonCASigner(t *testing.T) {
+	leafCert, leafKey, err := Certificate(CertificateSpec{CommonName: "leaf", Days: 30}, nil, nil)
+	require.NoError(t, err)
+
+	_, _, err = Certificate(CertificateSpec{CommonName: "web", Days: 30}, leafCert, leafKey)
+	assert.ErrorContains(t, err, "is not a CA")
+}
@@ -0,0 +1,113 @@
+package generate
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	AlnumCharset  = "alnum"
+	HexCharset    = "hex"
+	Base64Charset = "base64"
+
+	alnumLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	// maxRandomLength keeps a typo like 3200 instead of 32 from producing a huge value
+	maxRandomLength = 4096
+)
+
+// RandomSpec describes a random value. For alnum and hex Length is the number of
+// characters, for base64 it is the number of random bytes that are encoded, like
+// openssl rand -base64.
+type RandomSpec struct {
+	Length  int
+	Charset string
+}
+
+// ParseRandomSource parses a key=length[:charset] source such as db-password=32:alnum.
+// The charset defaults to alnum.
+func ParseRandomSource(source string) (string, RandomSpec, error) {
+	key, specStr, found := strings.Cut(source, "=")
+	if !found || key == "" || specStr == "" {
+		return "", RandomSpec{}, fmt.Errorf("invalid random source %q, expected key=length[:alnum|hex|base64]", source)
+	}
+	spec, err := ParseRandomSpec(specStr)
+	if err != nil {
+		return "", RandomSpec{}, fmt.Errorf("invalid random source %q: %w", source, err)
+	}
+	return key, spec, nil
+}
+
+// ParseRandomSpec parses a length[:charset] spec such as 64:hex.
+func ParseRandomSpec(spec string) (RandomSpec, error) {
+	lengthStr, charset, found := strings.Cut(spec, ":")
+	if !found {
+		charset = AlnumCharset
+	}
+	length, err := strconv.Atoi(lengthStr)
+	if err != nil || length <= 0 || length > maxRandomLength {
+		return RandomSpec{}, fmt.Errorf("length must be a number between 1 and %d", maxRandomLength)
+	}
+	switch charset {
+	case AlnumCharset, HexCharset, Base64Charset:
+	default:
+		return RandomSpec{}, fmt.Errorf("unknown charset %q, use alnum, hex or base64", charset)
+	}
+	return RandomSpec{Length: length, Charset: charset}, nil
+}
+
+// Generate returns a new random value read from crypto/rand.
+func (s RandomSpec) Generate() ([]byte, error) {
+	switch s.Charset {
+	case HexCharset:
+		raw, err := randomBytes((s.Length + 1) / 2)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(hex.EncodeToString(raw)[:s.Length]), nil
+	case Base64Charset:
+		raw, err := randomBytes(s.Length)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(base64.StdEncoding.EncodeToString(raw)), nil
+	default:
+		value := make([]byte, s.Length)
+		max := big.NewInt(int64(len(alnumLetters)))
+		for i := range value {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate random value: %w", err)
+			}
+			value[i] = alnumLetters[n.Int64()]
+		}
+		return value, nil
+	}
+}
+
+// EncodedLength returns the length of the values Generate returns.
+func (s RandomSpec) EncodedLength() int {
+	if s.Charset == Base64Charset {
+		return base64.StdEncoding.EncodedLen(s.Length)
+	}
+	return s.Length
+}
+
+func (s RandomSpec) String() string {
+	if s.Charset == Base64Charset {
+		return fmt.Sprintf("%d random bytes, base64 encoded", s.Length)
+	}
+	return fmt.Sprintf("%d %s characters", s.Length, s.Charset)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return raw, nil
+}
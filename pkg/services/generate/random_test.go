@@ -0,0 +1,46 @@
+package generate
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRandomSource(t *testing.T) {
+	key, spec, err := ParseRandomSource("token=64:hex")
+	require.NoError(t, err)
+	assert.Equal(t, "token", key)
+	assert.Equal(t, RandomSpec{Length: 64, Charset: HexCharset}, spec)
+
+	_, spec, err = ParseRandomSource("db-password=32")
+	require.NoError(t, err)
+	assert.Equal(t, AlnumCharset, spec.Charset)
+
+	for _, source := range []string{"token", "=32", "token=abc", "token=0", "token=32:emoji"} {
+		_, _, err := ParseRandomSource(source)
+		assert.Error(t, err, source)
+	}
+}
+
+func TestRandomSpec_Generate(t *testing.T) {
+	value, err := RandomSpec{Length: 32, Charset: AlnumCharset}.Generate()
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[a-zA-Z0-9]{32}$`), string(value))
+
+	value, err = RandomSpec{Length: 63, Charset: HexCharset}.Generate()
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{63}$`), string(value))
+
+	value, err = RandomSpec{Length: 32, Charset: Base64Charset}.Generate()
+	require.NoError(t, err)
+	raw, err := base64.StdEncoding.DecodeString(string(value))
+	require.NoError(t, err)
+	assert.Len(t, raw, 32)
+
+	other, err := RandomSpec{Length: 32, Charset: Base64Charset}.Generate()
+	require.NoError(t, err)
+	assert.NotEqual(t, value, other)
+}
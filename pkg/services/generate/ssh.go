@@ -0,0 +1,69 @@
+package generate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	Ed25519KeyType = "ed25519"
+	EcdsaKeyType   = "ecdsa"
+	RsaKeyType     = "rsa"
+
+	rsaKeyBits = 4096
+)
+
+// ParseSSHKeyType checks an SSH key type, an empty type selects ed25519.
+func ParseSSHKeyType(keyType string) (string, error) {
+	switch keyType {
+	case "":
+		return Ed25519KeyType, nil
+	case Ed25519KeyType, EcdsaKeyType, RsaKeyType:
+		return keyType, nil
+	default:
+		return "", fmt.Errorf("unknown SSH key type %q, use ed25519, ecdsa or rsa", keyType)
+	}
+}
+
+// SSHKeyPair generates an SSH key pair and returns the private key in OpenSSH format and
+// the public key in authorized_keys format. ECDSA keys use P-256, RSA keys 4096 bits.
+func SSHKeyPair(keyType string, comment string) ([]byte, []byte, error) {
+	var privateKey crypto.Signer
+	var err error
+	switch keyType {
+	case Ed25519KeyType:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	case EcdsaKeyType:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case RsaKeyType:
+		privateKey, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return nil, nil, fmt.Errorf("unknown SSH key type %q, use ed25519, ecdsa or rsa", keyType)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate %s key: %w", keyType, err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(privateKey, comment)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKey, err := ssh.NewPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	authorizedKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(publicKey)), "\n")
+	if comment != "" {
+		authorizedKey += " " + comment
+	}
+	return pem.EncodeToMemory(block), []byte(authorizedKey + "\n"), nil
+}
@@ -0,0 +1,146 @@
+package generate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCertificateDays = 365
+	maxCertificateDays     = 3650
+)
+
+// CertificateSpec describes a certificate to generate, parsed from
+// cn=...,days=365[,dns=a;b][,is-ca][,ca=ref].
+type CertificateSpec struct {
+	CommonName string
+	Days       int
+	DNSNames   []string
+	IsCA       bool
+	// CARef references the CA that signs the certificate, it is self-signed when empty
+	CARef string
+}
+
+// ParseCertificateSpec parses a comma separated certificate spec.
+func ParseCertificateSpec(spec string) (CertificateSpec, error) {
+	result := CertificateSpec{Days: defaultCertificateDays}
+	for _, option := range strings.Split(spec, ",") {
+		name, value, hasValue := strings.Cut(option, "=")
+		switch name {
+		case "cn":
+			result.CommonName = value
+		case "days":
+			days, err := strconv.Atoi(value)
+			if err != nil || days <= 0 || days > maxCertificateDays {
+				return CertificateSpec{}, fmt.Errorf("days must be a number between 1 and %d", maxCertificateDays)
+			}
+			result.Days = days
+		case "dns":
+			for _, dnsName := range strings.Split(value, ";") {
+				if dnsName != "" {
+					result.DNSNames = append(result.DNSNames, dnsName)
+				}
+			}
+		case "is-ca":
+			isCA, err := strconv.ParseBool(value)
+			if hasValue && err != nil {
+				return CertificateSpec{}, fmt.Errorf("is-ca must be true or false")
+			}
+			result.IsCA = !hasValue || isCA
+		case "ca":
+			result.CARef = value
+		default:
+			return CertificateSpec{}, fmt.Errorf("unknown certificate option %q, use cn, days, dns, is-ca or ca", name)
+		}
+	}
+	if result.CommonName == "" {
+		return CertificateSpec{}, fmt.Errorf("the certificate needs a common name, use cn=...")
+	}
+	if result.IsCA && result.CARef != "" {
+		return CertificateSpec{}, fmt.Errorf("is-ca cannot be combined with ca, only self-signed CAs are generated")
+	}
+	return result, nil
+}
+
+// Certificate generates an ECDSA P-256 key and a certificate for it, signed by the CA
+// key pair given in PEM format or self-signed when caCert is empty. It returns the
+// certificate and the PKCS#8 private key in PEM format.
+func Certificate(spec CertificateSpec, caCert []byte, caKey []byte) ([]byte, []byte, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate certificate key: %w", err)
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	notBefore := time.Now().Add(-5 * time.Minute)
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: spec.CommonName},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.AddDate(0, 0, spec.Days),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  spec.IsCA,
+	}
+	if spec.IsCA {
+		template.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		dnsNames := spec.DNSNames
+		if len(dnsNames) == 0 {
+			dnsNames = []string{spec.CommonName}
+		}
+		for _, name := range dnsNames {
+			if ip := net.ParseIP(name); ip != nil {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			} else {
+				template.DNSNames = append(template.DNSNames, name)
+			}
+		}
+	}
+
+	parent := template
+	var signer crypto.Signer = privateKey
+	if len(caCert) > 0 {
+		ca, err := tls.X509KeyPair(caCert, caKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CA certificate and key pair: %w", err)
+		}
+		parent, err = x509.ParseCertificate(ca.Certificate[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CA certificate: %w", err)
+		}
+		if !parent.IsCA {
+			return nil, nil, fmt.Errorf("the certificate of %s is not a CA", parent.Subject.CommonName)
+		}
+		var ok bool
+		if signer, ok = ca.PrivateKey.(crypto.Signer); !ok {
+			return nil, nil, fmt.Errorf("the CA key cannot sign certificates")
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &privateKey.PublicKey, signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), nil
+}
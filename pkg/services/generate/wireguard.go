@@ -0,0 +1,25 @@
+package generate
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"fmt"
+)
+
+// WireGuardKeyPair generates a Curve25519 key pair like wg genkey and wg pubkey, both
+// base64 encoded.
+func WireGuardKeyPair() ([]byte, []byte, error) {
+	raw, err := randomBytes(32)
+	if err != nil {
+		return nil, nil, err
+	}
+	// clamp the scalar the way wg genkey does
+	raw[0] &= 248
+	raw[31] = (raw[31] & 127) | 64
+	privateKey, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate WireGuard key: %w", err)
+	}
+	return []byte(base64.StdEncoding.EncodeToString(privateKey.Bytes())),
+		[]byte(base64.StdEncoding.EncodeToString(privateKey.PublicKey().Bytes())), nil
+}
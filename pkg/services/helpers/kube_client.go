@@ -1,7 +1,13 @@
 package helpers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
 	"fmt"
+	"os"
+	"os/user"
+	"sopsctl/pkg/domain"
 
 	"github.com/fatih/color"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -38,10 +44,72 @@ func GetCtxNameFromCurrent() (string, error) {
 	return config.CurrentContext, nil
 }
 
+// ListKubeContexts returns the names of all contexts in the merged kubeconfig.
+func ListKubeContexts() ([]string, error) {
+	config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+	if err != nil {
+		return nil, err
+	}
+	var contexts []string
+	for name := range config.Contexts {
+		contexts = append(contexts, name)
+	}
+	return contexts, nil
+}
+
+// GetClusterIdentity returns the API server URL and CA certificate fingerprint of the
+// cluster the kubeconfig context points at.
+func GetClusterIdentity(ctxName string) (*domain.ClusterIdentity, error) {
+	config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+	if err != nil {
+		return nil, err
+	}
+	kubeCtx, ok := config.Contexts[ctxName]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", ctxName)
+	}
+	cluster, ok := config.Clusters[kubeCtx.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("cluster %q of context %q not found in kubeconfig", kubeCtx.Cluster, ctxName)
+	}
+	caData := cluster.CertificateAuthorityData
+	if len(caData) == 0 && cluster.CertificateAuthority != "" {
+		caData, err = os.ReadFile(cluster.CertificateAuthority)
+		if err != nil {
+			return nil, fmt.Errorf("read CA of cluster %q: %w", kubeCtx.Cluster, err)
+		}
+	}
+	return &domain.ClusterIdentity{Server: cluster.Server, CAFingerprint: caFingerprint(caData)}, nil
+}
+
+// caFingerprint returns the hex SHA-256 of the first PEM certificate, or an empty string.
+func caFingerprint(caData []byte) string {
+	block, _ := pem.Decode(caData)
+	if block == nil {
+		return ""
+	}
+	sum := sha256.Sum256(block.Bytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// CurrentUserName returns the name of the OS user running sopsctl, or an empty string.
+func CurrentUserName() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
+
 func PrintError(s string, err error) {
 	color.Red("%s: %v", s, err)
 }
 
+// PrintWarning prints a bold warning to stderr so it is not mixed into command output.
+func PrintWarning(s string) {
+	_, _ = color.New(color.FgYellow, color.Bold).Fprintln(os.Stderr, s)
+}
+
 func RandomString(i int) string {
 	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	b := make([]byte, i)
@@ -0,0 +1,94 @@
+package key
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sopsctl/pkg/domain"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+const keyBundleVersion = 1
+
+// SealKeyBundle encodes the bundle and encrypts it to the age recipients. The result is
+// ASCII armored so it can be stored and passed around as text.
+func SealKeyBundle(bundle *domain.KeyBundle, recipients []string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one age recipient is required")
+	}
+	var parsed []age.Recipient
+	for _, r := range recipients {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(r))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", r, err)
+		}
+		parsed = append(parsed, recipient)
+	}
+	bundle.Version = keyBundleVersion
+	plaintext, err := yaml.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	armorWriter := armor.NewWriter(out)
+	w, err := age.Encrypt(armorWriter, parsed...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := armorWriter.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// OpenKeyBundle decrypts a bundle created with SealKeyBundle, armored or binary.
+func OpenKeyBundle(data []byte, identities []age.Identity) (*domain.KeyBundle, error) {
+	var in io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		in = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	r, err := age.Decrypt(in, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt key bundle: %w", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt key bundle: %w", err)
+	}
+	bundle := &domain.KeyBundle{}
+	if err := yaml.Unmarshal(plaintext, bundle); err != nil {
+		return nil, fmt.Errorf("key bundle is not valid: %w", err)
+	}
+	if bundle.Version > keyBundleVersion {
+		return nil, fmt.Errorf("key bundle version %d is newer than this sopsctl supports (%d), upgrade sopsctl", bundle.Version, keyBundleVersion)
+	}
+	return bundle, nil
+}
+
+// ReadIdentityFile reads the age identities in an identity file such as keys.txt.
+func ReadIdentityFile(path string) ([]age.Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open identity file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("read identity file %s: %w", path, err)
+	}
+	return identities, nil
+}
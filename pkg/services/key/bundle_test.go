@@ -0,0 +1,80 @@
+package key
+
+import (
+	"sopsctl/pkg/domain"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealKeyBundle_RoundTrip(t *testing.T) {
+	team, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	clusterKey, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	addedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	bundle := &domain.KeyBundle{Contexts: map[string]domain.CTX{
+		"prod": {PrivateKey: clusterKey.String(), Namespace: "flux-system", SecretName: "sops-age", KeyName: "age.agekey", Source: "cluster", AddedAt: addedAt},
+	}}
+
+	sealed, err := SealKeyBundle(bundle, []string{team.Recipient().String()})
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), clusterKey.String())
+
+	opened, err := OpenKeyBundle(sealed, []age.Identity{team})
+	require.NoError(t, err)
+	assert.Equal(t, bundle.Contexts, opened.Contexts)
+	assert.Equal(t, keyBundleVersion, opened.Version)
+}
+
+func TestOpenKeyBundle_WrongIdentity(t *testing.T) {
+	team, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	sealed, err := SealKeyBundle(&domain.KeyBundle{}, []string{team.Recipient().String()})
+	require.NoError(t, err)
+
+	_, err = OpenKeyBundle(sealed, []age.Identity{other})
+
+	assert.Error(t, err)
+}
+
+func TestGlobalSopsKeyManager_ImportCtx_KeepsKeyLocally(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	uut := NewGlobalSopsKeyManager()
+
+	err = uut.ImportCtx("prod", domain.CTX{PrivateKey: identity.String(), SecretName: "sops-age", StorageMode: "cluster", Source: "cluster"})
+	require.NoError(t, err)
+
+	mode, err := uut.GetStorageMode("prod")
+	require.NoError(t, err)
+	assert.Equal(t, domain.LocalStorageMode, mode)
+	exported, err := uut.ExportCtx("prod")
+	require.NoError(t, err)
+	assert.Equal(t, identity.String(), exported.PrivateKey)
+	assert.Equal(t, "sops-age", exported.SecretName)
+}
+
+func TestGlobalSopsKeyManager_ImportCtx_KeepsKeyLocallyInGlobalClusterMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	uut := NewGlobalSopsKeyManager()
+	require.NoError(t, uut.storage.SetStorageMode(domain.InClusterStorageMode))
+
+	err = uut.ImportCtx("prod", domain.CTX{PrivateKey: identity.String(), Namespace: "flux-system", SecretName: "sops-age", KeyName: "age.agekey", Source: "cluster"})
+	require.NoError(t, err)
+
+	mode, err := uut.GetStorageMode("prod")
+	require.NoError(t, err)
+	assert.Equal(t, domain.LocalStorageMode, mode)
+	privateKey, err := uut.GetPrivateKey("prod")
+	require.NoError(t, err)
+	assert.Equal(t, identity.String(), privateKey)
+}
@@ -1,9 +1,13 @@
 package key
 
 import (
+	"fmt"
+	"slices"
 	"sopsctl/pkg/domain"
 	"sopsctl/pkg/services/helpers"
 	"sopsctl/pkg/services/storage"
+	"sort"
+	"strings"
 
 	"filippo.io/age"
 	"github.com/fatih/color"
@@ -12,6 +16,9 @@ import (
 type GlobalSopsKeyManager struct {
 	storage    domain.KeyStorage
 	currentCtx string
+	// warnedContexts holds the contexts a cluster mismatch was reported for, so the
+	// warning is printed once per invocation
+	warnedContexts map[string]bool
 }
 
 func (g GlobalSopsKeyManager) GetIdentityCurrentCtx() (age.Identity, error) {
@@ -36,7 +43,7 @@ func (g GlobalSopsKeyManager) RemoveKeyForContext(ctx string) error {
 
 // SavePrivateKey saves the private key for the current context unless the storage mode is InCluster.
 func (g GlobalSopsKeyManager) SavePrivateKey(key string) error {
-	inClusterStorageMode, err := g.isInClusterStorageMode()
+	inClusterStorageMode, err := g.isInClusterStorageMode(g.currentCtx)
 	if err != nil {
 		return err
 	}
@@ -51,8 +58,13 @@ func (g GlobalSopsKeyManager) SavePrivateKey(key string) error {
 	return nil
 }
 
-func (g GlobalSopsKeyManager) isInClusterStorageMode() (bool, error) {
-	mode, err := g.storage.GetStorageMode()
+// GetStorageMode returns the storage mode in effect for the context, falling back to the global mode.
+func (g GlobalSopsKeyManager) GetStorageMode(ctxName string) (domain.StorageMode, error) {
+	return g.storage.GetCtxStorageMode(ctxName)
+}
+
+func (g GlobalSopsKeyManager) isInClusterStorageMode(ctxName string) (bool, error) {
+	mode, err := g.storage.GetCtxStorageMode(ctxName)
 	if err != nil {
 		return false, err
 	}
@@ -62,13 +74,33 @@ func (g GlobalSopsKeyManager) isInClusterStorageMode() (bool, error) {
 	return false, nil
 }
 
-func (g GlobalSopsKeyManager) GetPublicKey(ctxName string) (string, error) {
-	inClusterStorageMode, err := g.isInClusterStorageMode()
+// IsEncryptOnly reports whether only a public key is stored for the context.
+func (g GlobalSopsKeyManager) IsEncryptOnly(ctxName string) (bool, error) {
+	ctx, err := g.storage.GetCtx(ctxName)
+	if err != nil {
+		return false, err
+	}
+	return ctx.IsEncryptOnly(), nil
+}
+
+func (g GlobalSopsKeyManager) GetPublicKey(kubeCtxName string) (string, error) {
+	ctxName := g.resolveCtx(kubeCtxName)
+	if strings.HasPrefix(ctxName, domain.SopsAgeKeyCtxPrefix) {
+		identity, err := g.getSopsAgeIdentity(ctxName)
+		if err != nil {
+			return "", err
+		}
+		return identity.Recipient().String(), nil
+	}
+	if ctx, err := g.storage.GetCtx(ctxName); err == nil && ctx.IsEncryptOnly() {
+		return ctx.PublicKey, nil
+	}
+	inClusterStorageMode, err := g.isInClusterStorageMode(ctxName)
 	if err != nil {
 		return "", err
 	}
 	if inClusterStorageMode {
-		identity, err := g.getIdentityFromCluster(ctxName)
+		identity, err := g.getIdentityFromCluster(kubeCtxName, ctxName)
 		if err != nil {
 			return "", err
 		}
@@ -85,12 +117,18 @@ func (g GlobalSopsKeyManager) GetPublicKey(ctxName string) (string, error) {
 	return identity.Recipient().String(), nil
 }
 
-func (g GlobalSopsKeyManager) getIdentityFromCluster(ctxName string) (*age.X25519Identity, error) {
+// getIdentityFromCluster reads the key of the stored context live from the cluster. The
+// kube client uses the kubeconfig context name, which differs from the stored name when
+// the stored context was resolved by cluster identity.
+func (g GlobalSopsKeyManager) getIdentityFromCluster(kubeCtxName string, ctxName string) (*age.X25519Identity, error) {
 	ctx, err := g.storage.GetCtx(ctxName)
 	if err != nil {
 		return nil, err
 	}
-	strategy, err := createClusterKeyGetterStrategy(ctxName, ctx.Namespace, ctx.SecretName, ctx.KeyName)
+	if !ctx.HasClusterReference() {
+		return nil, fmt.Errorf("no cluster secret reference stored for context %s, run add-key while in cluster storage mode", ctxName)
+	}
+	strategy, err := createClusterKeyGetterStrategy(kubeCtxName, ctx.Namespace, ctx.SecretName, ctx.KeyName)
 	if err != nil {
 		return nil, err
 	}
@@ -109,13 +147,24 @@ func (g GlobalSopsKeyManager) ListContextsWithKeys() ([]string, error) {
 	return g.storage.ListContextsWithKeys()
 }
 
-func (g GlobalSopsKeyManager) GetPrivateKey(ctxName string) (string, error) {
-	isInClusterStorageMode, err := g.isInClusterStorageMode()
+func (g GlobalSopsKeyManager) GetPrivateKey(kubeCtxName string) (string, error) {
+	ctxName := g.resolveCtx(kubeCtxName)
+	if strings.HasPrefix(ctxName, domain.SopsAgeKeyCtxPrefix) {
+		identity, err := g.getSopsAgeIdentity(ctxName)
+		if err != nil {
+			return "", err
+		}
+		return identity.String(), nil
+	}
+	if ctx, err := g.storage.GetCtx(ctxName); err == nil && ctx.IsEncryptOnly() {
+		return "", fmt.Errorf("context %s is encrypt-only, only its public key is stored so it cannot be used to decrypt", ctxName)
+	}
+	isInClusterStorageMode, err := g.isInClusterStorageMode(ctxName)
 	if err != nil {
 		return "", err
 	}
 	if isInClusterStorageMode {
-		identity, err := g.getIdentityFromCluster(ctxName)
+		identity, err := g.getIdentityFromCluster(kubeCtxName, ctxName)
 		if err != nil {
 			return "", err
 		}
@@ -134,7 +183,14 @@ func (g GlobalSopsKeyManager) GetPrivateKey(ctxName string) (string, error) {
 }
 
 func (g GlobalSopsKeyManager) AddKeyFromCluster(ctxName string, namespace string, secretName string, secretKey string) (string, error) {
-	isInClusterStorageMode, err := g.isInClusterStorageMode()
+	var err error
+	if ctxName == "" {
+		ctxName, err = helpers.GetCtxNameFromCurrent()
+		if err != nil {
+			return "", err
+		}
+	}
+	isInClusterStorageMode, err := g.isInClusterStorageMode(ctxName)
 	if err != nil {
 		return "", err
 	}
@@ -143,41 +199,335 @@ func (g GlobalSopsKeyManager) AddKeyFromCluster(ctxName string, namespace string
 		if err != nil {
 			return "", err
 		}
+		g.recordClusterIdentity(ctxName)
 		return "Added sops key reference" + ": " + color.GreenString(ctxName) + "/" + color.GreenString(namespace) + "/" + color.GreenString(secretName) + ":(" + color.GreenString(secretKey) + ")", err
 	}
 	clusterKeyGetter, err := createClusterKeyGetterStrategy(ctxName, namespace, secretName, secretKey)
 	if err != nil {
 		return "", err
 	}
+	privateKey, err := clusterKeyGetter.Key()
+	if err != nil {
+		return "", err
+	}
+	_, err = age.ParseX25519Identity(privateKey)
+	if err != nil {
+		return "", err
+	}
+	err = g.storage.SaveClusterKey(privateKey, ctxName, namespace, secretName, secretKey)
+	if err != nil {
+		return "", err
+	}
+	g.recordClusterIdentity(ctxName)
+	return "Added sops key from cluster secret" + ": " + color.GreenString(ctxName) + "/" + color.GreenString(namespace) + "/" + color.GreenString(secretName) + ":(" + color.GreenString(secretKey) + ") in local storage", nil
+}
+
+// RefreshKey re-fetches a locally stored key from the cluster secret recorded when it
+// was added and stores it again when it was rotated.
+func (g GlobalSopsKeyManager) RefreshKey(ctxName string) (domain.KeyRefreshStatus, error) {
+	ctx, err := g.storage.GetCtx(ctxName)
+	if err != nil {
+		return "", err
+	}
+	if ctx.IsEncryptOnly() {
+		return domain.KeyRefreshEncryptOnly, nil
+	}
+	if !ctx.HasClusterReference() {
+		return domain.KeyRefreshNoSource, nil
+	}
+	isInClusterStorageMode, err := g.isInClusterStorageMode(ctxName)
+	if err != nil {
+		return "", err
+	}
+	if isInClusterStorageMode {
+		return domain.KeyRefreshLive, nil
+	}
+	if current, moved := g.clusterMoved(ctxName, ctx); moved {
+		return "", fmt.Errorf("context %s now points at %s instead of the cluster its key was added for (%s), remove the key and run add-key again if this is intended", ctxName, current.Server, ctx.Server)
+	}
+
+	clusterKeyGetter, err := createClusterKeyGetterStrategy(ctxName, ctx.Namespace, ctx.SecretName, ctx.KeyName)
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := clusterKeyGetter.Key()
+	if err != nil {
+		return "", err
+	}
+	_, err = age.ParseX25519Identity(privateKey)
+	if err != nil {
+		return "", err
+	}
+	if privateKey == ctx.PrivateKey {
+		return domain.KeyRefreshUnchanged, nil
+	}
+	err = g.storage.RefreshClusterKey(privateKey, ctxName)
+	if err != nil {
+		return "", err
+	}
+	g.recordClusterIdentity(ctxName)
+	return domain.KeyRefreshChanged, nil
+}
+
+// ExportCtx returns the stored context with its private key resolved, reading it live
+// from the cluster for contexts in cluster storage mode.
+func (g GlobalSopsKeyManager) ExportCtx(ctxName string) (*domain.CTX, error) {
+	ctx, err := g.storage.GetCtx(ctxName)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.IsEncryptOnly() {
+		return ctx, nil
+	}
+	privateKey, err := g.GetPrivateKey(ctxName)
+	if err != nil {
+		return nil, fmt.Errorf("read SOPS key for context %s: %w", ctxName, err)
+	}
+	ctx.PrivateKey = privateKey
+	return ctx, nil
+}
+
+// ImportCtx stores a context exported with ExportCtx. Imported private keys are kept in
+// local storage mode, since the cluster they came from may no longer exist.
+func (g GlobalSopsKeyManager) ImportCtx(ctxName string, ctx domain.CTX) error {
+	if ctx.PrivateKey != "" {
+		if _, err := age.ParseX25519Identity(ctx.PrivateKey); err != nil {
+			return fmt.Errorf("invalid SOPS key for context %s: %w", ctxName, err)
+		}
+		// Set explicitly, an empty mode would follow a global cluster storage mode
+		ctx.StorageMode = domain.LocalStorageMode.ToString()
+	}
+	return g.storage.SaveCtx(ctxName, ctx)
+}
+
+// FindCtxForRecipients returns the stored context whose key is one of the age recipients,
+// checking the preferred context first. Contexts that read their key live from the
+// cluster are only asked when no locally stored key matches.
+func (g GlobalSopsKeyManager) FindCtxForRecipients(recipients []string, preferred string) (string, error) {
+	contexts, err := g.storage.ListContextsWithKeys()
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(contexts)
+	if slices.Contains(contexts, preferred) {
+		contexts = append([]string{preferred}, slices.DeleteFunc(contexts, func(ctx string) bool { return ctx == preferred })...)
+	}
+
+	var liveContexts []string
+	for _, ctxName := range contexts {
+		ctx, err := g.storage.GetCtx(ctxName)
+		if err != nil || ctx.IsEncryptOnly() {
+			continue
+		}
+		if ctx.PrivateKey == "" {
+			liveContexts = append(liveContexts, ctxName)
+			continue
+		}
+		identity, err := age.ParseX25519Identity(ctx.PrivateKey)
+		if err == nil && slices.Contains(recipients, identity.Recipient().String()) {
+			return ctxName, nil
+		}
+	}
+	for _, ctxName := range liveContexts {
+		publicKey, err := g.GetPublicKey(ctxName)
+		if err == nil && slices.Contains(recipients, publicKey) {
+			return ctxName, nil
+		}
+	}
+
+	fallback, err := g.storage.GetSopsKeyFallback()
+	if err != nil {
+		return "", err
+	}
+	hint := "add the matching key with add-key or select a context with --cluster"
+	if fallback {
+		identities, err := loadSopsAgeIdentities()
+		if err != nil {
+			return "", err
+		}
+		for _, identity := range identities {
+			if recipient := identity.Recipient().String(); slices.Contains(recipients, recipient) {
+				return domain.SopsAgeKeyCtxPrefix + recipient, nil
+			}
+		}
+	} else {
+		hint += ", or look in the standard sops key locations with 'sopsctl key --set-sops-fallback=true'"
+	}
+	return "", fmt.Errorf("none of the stored SOPS keys can decrypt the file, it is encrypted for:\n  %s\n%s", strings.Join(recipients, "\n  "), hint)
+}
+
+// ResolveClusters expands a comma separated list of contexts and cluster group names
+// into the contexts it refers to, in order and without duplicates.
+func (g GlobalSopsKeyManager) ResolveClusters(cluster string) ([]string, error) {
+	groups, err := g.storage.GetClusterGroups()
+	if err != nil {
+		return nil, err
+	}
+	var clusters []string
+	for _, name := range strings.Split(cluster, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		members, isGroup := groups[name]
+		if !isGroup {
+			members = []string{name}
+		}
+		for _, member := range members {
+			if !slices.Contains(clusters, member) {
+				clusters = append(clusters, member)
+			}
+		}
+	}
+	if len(clusters) == 0 {
+		return nil, fmt.Errorf("no cluster given")
+	}
+	return clusters, nil
+}
+
+// GetPublicKeys returns the public key of every context the cluster list or group refers to.
+func (g GlobalSopsKeyManager) GetPublicKeys(cluster string) ([]string, error) {
+	clusters, err := g.ResolveClusters(cluster)
+	if err != nil {
+		return nil, err
+	}
+	var publicKeys []string
+	for _, ctxName := range clusters {
+		if _, err := g.storage.GetCtx(ctxName); err != nil && !strings.HasPrefix(ctxName, domain.SopsAgeKeyCtxPrefix) {
+			return nil, fmt.Errorf("no SOPS key stored for context %s, add it with add-key", ctxName)
+		}
+		publicKey, err := g.GetPublicKey(ctxName)
+		if err != nil {
+			return nil, fmt.Errorf("get SOPS public key for context %s: %w", ctxName, err)
+		}
+		publicKeys = append(publicKeys, publicKey)
+	}
+	return publicKeys, nil
+}
+
+// getSopsAgeIdentity returns the identity from the standard sops key locations whose
+// recipient is named by the pseudo context.
+func (g GlobalSopsKeyManager) getSopsAgeIdentity(ctxName string) (*age.X25519Identity, error) {
+	fallback, err := g.storage.GetSopsKeyFallback()
+	if err != nil {
+		return nil, err
+	}
+	if !fallback {
+		return nil, fmt.Errorf("context %s refers to the standard sops key locations, enable them with 'sopsctl key --set-sops-fallback=true'", ctxName)
+	}
+	identities, err := loadSopsAgeIdentities()
+	if err != nil {
+		return nil, err
+	}
+	recipient := strings.TrimPrefix(ctxName, domain.SopsAgeKeyCtxPrefix)
+	for _, identity := range identities {
+		if identity.Recipient().String() == recipient {
+			return identity, nil
+		}
+	}
+	return nil, fmt.Errorf("no key for %s found in %s, %s or the default sops keys.txt", recipient, sopsAgeKeyFileEnv, sopsAgeKeyEnv)
+}
+
+func (g GlobalSopsKeyManager) GetCtx(ctxName string) (*domain.CTX, error) {
+	return g.storage.GetCtx(ctxName)
+}
+
+// AddPublicKey registers an encrypt-only context holding just the age public key.
+func (g GlobalSopsKeyManager) AddPublicKey(ctxName string, publicKey string) (string, error) {
+	var err error
 	if ctxName == "" {
 		ctxName, err = helpers.GetCtxNameFromCurrent()
 		if err != nil {
 			return "", err
 		}
 	}
-	privateKey, err := clusterKeyGetter.Key()
+	recipient, err := age.ParseX25519Recipient(strings.TrimSpace(publicKey))
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("invalid age public key: %w", err)
 	}
-	_, err = age.ParseX25519Identity(privateKey)
+	err = g.storage.SavePublicKey(recipient.String(), ctxName)
 	if err != nil {
 		return "", err
 	}
-	err = g.storage.SavePrivateKey(privateKey, ctxName)
+	g.recordClusterIdentity(ctxName)
+	return "Added encrypt-only public key for context" + ": " + color.GreenString(ctxName) + " (" + color.GreenString(recipient.String()) + ")", nil
+}
+
+// getClusterIdentity is a variable to allow replacing the kubeconfig in tests
+var getClusterIdentity = helpers.GetClusterIdentity
+
+// recordClusterIdentity stores the identity of the cluster the context points at. Contexts
+// missing from the kubeconfig, such as encrypt-only ones, are stored without identity.
+func (g GlobalSopsKeyManager) recordClusterIdentity(ctxName string) {
+	identity, err := getClusterIdentity(ctxName)
 	if err != nil {
-		return "", err
+		return
 	}
-	return "Added sops key from cluster secret" + ": " + color.GreenString(ctxName) + "/" + color.GreenString(namespace) + "/" + color.GreenString(secretName) + ":(" + color.GreenString(secretKey) + ") in local storage", nil
+	if err := g.storage.SetCtxClusterIdentity(ctxName, *identity); err != nil {
+		helpers.PrintError("failed to record cluster identity", err)
+	}
+}
+
+// clusterMoved reports whether the kubeconfig context now points at a different cluster
+// than the one recorded when the key was added.
+func (g GlobalSopsKeyManager) clusterMoved(ctxName string, ctx *domain.CTX) (*domain.ClusterIdentity, bool) {
+	if ctx.ClusterIdentity().IsZero() {
+		return nil, false
+	}
+	current, err := getClusterIdentity(ctxName)
+	if err != nil {
+		return nil, false
+	}
+	return current, !ctx.ClusterIdentity().Matches(*current)
+}
+
+// resolveCtx returns the stored context to use for a kubeconfig context name. A stored
+// context of the same name is used, with a warning when the name now points at another
+// cluster. Otherwise the stored context recorded for the same cluster is used, so keys
+// keep working when kubeconfigs name a cluster differently. The returned name is only
+// for config lookups, the cluster itself is reached through the kubeconfig name.
+func (g GlobalSopsKeyManager) resolveCtx(ctxName string) string {
+	if strings.HasPrefix(ctxName, domain.SopsAgeKeyCtxPrefix) {
+		return ctxName
+	}
+	if ctx, err := g.storage.GetCtx(ctxName); err == nil {
+		if current, moved := g.clusterMoved(ctxName, ctx); moved && !g.warnedContexts[ctxName] {
+			g.warnedContexts[ctxName] = true
+			helpers.PrintWarning(fmt.Sprintf("WARNING: context %s now points at %s, but its SOPS key was added for %s. The key may belong to a different cluster.", ctxName, current.Server, ctx.Server))
+		}
+		return ctxName
+	}
+
+	current, err := getClusterIdentity(ctxName)
+	if err != nil {
+		return ctxName
+	}
+	contexts, err := g.storage.ListContextsWithKeys()
+	if err != nil {
+		return ctxName
+	}
+	sort.Strings(contexts)
+	for _, stored := range contexts {
+		ctx, err := g.storage.GetCtx(stored)
+		if err == nil && !ctx.ClusterIdentity().IsZero() && ctx.ClusterIdentity().Matches(*current) {
+			return stored
+		}
+	}
+	return ctxName
 }
 
 func NewGlobalSopsKeyManager() *GlobalSopsKeyManager {
 	localUserKeyStorageService := storage.NewLocalUserKeyStorageService()
 	return &GlobalSopsKeyManager{
-		storage: *localUserKeyStorageService,
+		storage:        *localUserKeyStorageService,
+		warnedContexts: map[string]bool{},
 	}
 }
 
-func createClusterKeyGetterStrategy(ctxName string, namespace string, secretName string, secretKey string) (domain.KeyStrategy, error) {
+// createClusterKeyGetterStrategy is a variable to allow replacing the cluster in tests
+var createClusterKeyGetterStrategy = newClusterKeyGetterStrategy
+
+func newClusterKeyGetterStrategy(ctxName string, namespace string, secretName string, secretKey string) (domain.KeyStrategy, error) {
 	client, err := helpers.GetKubeClientForContext(ctxName)
 	if err != nil {
 		return nil, err
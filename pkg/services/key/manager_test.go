@@ -0,0 +1,223 @@
+package key
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/domain"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalSopsKeyManager_AddPublicKey_EncryptOnly(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	uut := NewGlobalSopsKeyManager()
+
+	_, err = uut.AddPublicKey("prod", identity.Recipient().String())
+	require.NoError(t, err)
+
+	publicKey, err := uut.GetPublicKey("prod")
+	require.NoError(t, err)
+	assert.Equal(t, identity.Recipient().String(), publicKey)
+
+	encryptOnly, err := uut.IsEncryptOnly("prod")
+	require.NoError(t, err)
+	assert.True(t, encryptOnly)
+
+	_, err = uut.GetPrivateKey("prod")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "encrypt-only")
+
+	contexts, err := uut.ListContextsWithKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod"}, contexts)
+}
+
+func TestGlobalSopsKeyManager_AddPublicKey_InvalidKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	uut := NewGlobalSopsKeyManager()
+
+	_, err := uut.AddPublicKey("prod", "AGE-SECRET-KEY-1QQQQ")
+
+	assert.Error(t, err)
+}
+
+func TestGlobalSopsKeyManager_RefreshKey_SkipsContextsWithoutSource(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	uut := NewGlobalSopsKeyManager()
+	_, err = uut.AddPublicKey("encrypt-only", identity.Recipient().String())
+	require.NoError(t, err)
+	require.NoError(t, uut.storage.SavePrivateKey(identity.String(), "legacy"))
+
+	status, err := uut.RefreshKey("encrypt-only")
+	require.NoError(t, err)
+	assert.Equal(t, domain.KeyRefreshEncryptOnly, status)
+
+	status, err = uut.RefreshKey("legacy")
+	require.NoError(t, err)
+	assert.Equal(t, domain.KeyRefreshNoSource, status)
+
+	_, err = uut.RefreshKey("unknown")
+	assert.Error(t, err)
+}
+
+func TestGlobalSopsKeyManager_FindCtxForRecipients(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	uut := NewGlobalSopsKeyManager()
+	dev, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	prod, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, uut.storage.SavePrivateKey(dev.String(), "dev"))
+	require.NoError(t, uut.storage.SavePrivateKey(prod.String(), "prod"))
+	_, err = uut.AddPublicKey("prod-encrypt-only", prod.Recipient().String())
+	require.NoError(t, err)
+
+	ctxName, err := uut.FindCtxForRecipients([]string{prod.Recipient().String()}, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", ctxName)
+
+	unknown, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	_, err = uut.FindCtxForRecipients([]string{unknown.Recipient().String()}, "dev")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), unknown.Recipient().String())
+}
+
+func TestGlobalSopsKeyManager_FindCtxForRecipients_SopsKeyFallback(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("SOPS_AGE_KEY", "")
+	personal, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".config", "sops", "age"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".config", "sops", "age", "keys.txt"), []byte("# personal\n"+personal.String()+"\n"), 0600))
+	uut := NewGlobalSopsKeyManager()
+	recipients := []string{personal.Recipient().String()}
+
+	_, err = uut.FindCtxForRecipients(recipients, "")
+	require.Error(t, err, "the fallback is disabled by default")
+
+	require.NoError(t, uut.storage.SetSopsKeyFallback(true))
+	ctxName, err := uut.FindCtxForRecipients(recipients, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.SopsAgeKeyCtxPrefix+personal.Recipient().String(), ctxName)
+
+	privateKey, err := uut.GetPrivateKey(ctxName)
+	require.NoError(t, err)
+	assert.Equal(t, personal.String(), privateKey)
+}
+
+func TestGlobalSopsKeyManager_ResolveClusters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	uut := NewGlobalSopsKeyManager()
+	require.NoError(t, uut.storage.SetClusterGroup("production", []string{"prod-eu", "prod-us"}))
+
+	clusters, err := uut.ResolveClusters("staging, production,prod-us")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"staging", "prod-eu", "prod-us"}, clusters)
+
+	_, err = uut.ResolveClusters(" , ")
+	assert.Error(t, err)
+}
+
+func stubClusterIdentities(t *testing.T, identities map[string]domain.ClusterIdentity) {
+	original := getClusterIdentity
+	getClusterIdentity = func(ctxName string) (*domain.ClusterIdentity, error) {
+		identity, ok := identities[ctxName]
+		if !ok {
+			return nil, fmt.Errorf("context %q not found in kubeconfig", ctxName)
+		}
+		return &identity, nil
+	}
+	t.Cleanup(func() { getClusterIdentity = original })
+}
+
+func TestGlobalSopsKeyManager_ResolvesContextByClusterIdentity(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	prod := domain.ClusterIdentity{Server: "https://prod.example.com", CAFingerprint: "ab12"}
+	stubClusterIdentities(t, map[string]domain.ClusterIdentity{
+		"prod":       prod,
+		"my-prod":    {Server: "https://10.0.0.1", CAFingerprint: "ab12"},
+		"my-staging": {Server: "https://staging.example.com", CAFingerprint: "cd34"},
+	})
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	uut := NewGlobalSopsKeyManager()
+	require.NoError(t, uut.storage.SavePrivateKey(identity.String(), "prod"))
+	uut.recordClusterIdentity("prod")
+
+	ctx, err := uut.GetCtx("prod")
+	require.NoError(t, err)
+	assert.Equal(t, prod, ctx.ClusterIdentity())
+
+	privateKey, err := uut.GetPrivateKey("my-prod")
+	require.NoError(t, err)
+	assert.Equal(t, identity.String(), privateKey)
+
+	assert.Equal(t, "my-staging", uut.resolveCtx("my-staging"))
+}
+
+type staticKeyStrategy string
+
+func (s staticKeyStrategy) Key() (string, error) {
+	return string(s), nil
+}
+
+func TestGlobalSopsKeyManager_ResolvesContextByClusterIdentityInClusterStorageMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	stubClusterIdentities(t, map[string]domain.ClusterIdentity{
+		"my-prod": {Server: "https://10.0.0.1", CAFingerprint: "ab12"},
+	})
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	var kubeContexts []string
+	original := createClusterKeyGetterStrategy
+	createClusterKeyGetterStrategy = func(ctxName string, namespace string, secretName string, secretKey string) (domain.KeyStrategy, error) {
+		kubeContexts = append(kubeContexts, ctxName)
+		if ctxName != "my-prod" {
+			return nil, fmt.Errorf("context %q not found in kubeconfig", ctxName)
+		}
+		return staticKeyStrategy(identity.String()), nil
+	}
+	t.Cleanup(func() { createClusterKeyGetterStrategy = original })
+
+	uut := NewGlobalSopsKeyManager()
+	require.NoError(t, uut.storage.SetStorageMode(domain.InClusterStorageMode))
+	require.NoError(t, uut.storage.SaveCtxReference("prod", "flux-system", "sops-age", "age.agekey"))
+	require.NoError(t, uut.storage.SetCtxClusterIdentity("prod", domain.ClusterIdentity{Server: "https://prod.example.com", CAFingerprint: "ab12"}))
+
+	privateKey, err := uut.GetPrivateKey("my-prod")
+	require.NoError(t, err)
+	assert.Equal(t, identity.String(), privateKey)
+	publicKey, err := uut.GetPublicKey("my-prod")
+	require.NoError(t, err)
+	assert.Equal(t, identity.Recipient().String(), publicKey)
+	assert.Equal(t, []string{"my-prod", "my-prod"}, kubeContexts)
+}
+
+func TestGlobalSopsKeyManager_RefreshKey_RefusesWhenContextPointsAtAnotherCluster(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	stubClusterIdentities(t, map[string]domain.ClusterIdentity{
+		"prod": {Server: "https://new-prod.example.com", CAFingerprint: "ef56"},
+	})
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	uut := NewGlobalSopsKeyManager()
+	require.NoError(t, uut.storage.SaveClusterKey(identity.String(), "prod", "flux-system", "sops-age", "age.agekey"))
+	require.NoError(t, uut.storage.SetCtxClusterIdentity("prod", domain.ClusterIdentity{Server: "https://prod.example.com", CAFingerprint: "ab12"}))
+
+	_, err = uut.RefreshKey("prod")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "new-prod.example.com")
+}
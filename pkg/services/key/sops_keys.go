@@ -0,0 +1,81 @@
+package key
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"k8s.io/client-go/util/homedir"
+)
+
+const (
+	sopsAgeKeyFileEnv = "SOPS_AGE_KEY_FILE"
+	sopsAgeKeyEnv     = "SOPS_AGE_KEY"
+)
+
+// loadSopsAgeIdentities reads the age identities from the locations sops itself uses:
+// the SOPS_AGE_KEY_FILE and SOPS_AGE_KEY environment variables and the default
+// keys.txt under the user config directory. A missing default keys.txt is not an error.
+func loadSopsAgeIdentities() ([]*age.X25519Identity, error) {
+	var identities []*age.X25519Identity
+	if keyFile := os.Getenv(sopsAgeKeyFileEnv); keyFile != "" {
+		fileIdentities, err := readSopsAgeKeyFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", sopsAgeKeyFileEnv, err)
+		}
+		identities = append(identities, fileIdentities...)
+	}
+	if keys := os.Getenv(sopsAgeKeyEnv); keys != "" {
+		envIdentities, err := parseSopsAgeIdentities(keys)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", sopsAgeKeyEnv, err)
+		}
+		identities = append(identities, envIdentities...)
+	}
+	if keyFile := defaultSopsAgeKeyFile(); keyFile != "" {
+		fileIdentities, err := readSopsAgeKeyFile(keyFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s: %w", keyFile, err)
+		}
+		identities = append(identities, fileIdentities...)
+	}
+	return identities, nil
+}
+
+func readSopsAgeKeyFile(path string) ([]*age.X25519Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseSopsAgeIdentities(string(data))
+}
+
+func parseSopsAgeIdentities(keys string) ([]*age.X25519Identity, error) {
+	parsed, err := age.ParseIdentities(strings.NewReader(keys))
+	if err != nil {
+		return nil, err
+	}
+	var identities []*age.X25519Identity
+	for _, identity := range parsed {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			identities = append(identities, x25519)
+		}
+	}
+	return identities, nil
+}
+
+// defaultSopsAgeKeyFile returns $XDG_CONFIG_HOME/sops/age/keys.txt, falling back to
+// ~/.config/sops/age/keys.txt.
+func defaultSopsAgeKeyFile() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		hd := homedir.HomeDir()
+		if hd == "" {
+			return ""
+		}
+		configDir = filepath.Join(hd, ".config")
+	}
+	return filepath.Join(configDir, "sops", "age", "keys.txt")
+}
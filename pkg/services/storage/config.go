@@ -5,14 +5,26 @@ import (
 	"os"
 	"path/filepath"
 	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/file"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// currentConfigVersion is the schema version written by this build. Files with an
+// older version are migrated on read, files with a newer version are refused.
+const currentConfigVersion = 1
+
 type ConfigFile struct {
-	StorageMode string
-	FilePath    string
-	Contexts    map[string]domain.CTX
+	Version        int
+	StorageMode    string
+	AuditHashChain bool
+	// SopsKeyFallback makes the keys in the standard sops locations decryption candidates.
+	SopsKeyFallback bool
+	// ClusterGroups maps a group name to the contexts a secret for the group is encrypted for.
+	ClusterGroups map[string][]string
+	FilePath      string `yaml:"-"`
+	Contexts      map[string]domain.CTX
 }
 
 func (c *ConfigFile) SaveStorageMode(mode string) error {
@@ -28,6 +40,80 @@ func (c *ConfigFile) GetStorageMode() (string, error) {
 	return c.StorageMode, nil
 }
 
+func (c *ConfigFile) SaveAuditHashChain(enabled bool) error {
+	c.AuditHashChain = enabled
+	err := c.SaveConfigFile()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *ConfigFile) SaveSopsKeyFallback(enabled bool) error {
+	c.SopsKeyFallback = enabled
+	err := c.SaveConfigFile()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveClusterGroup stores the members of the group, no members removes the group.
+func (c *ConfigFile) SaveClusterGroup(name string, members []string) error {
+	if len(members) == 0 {
+		delete(c.ClusterGroups, name)
+	} else {
+		if c.ClusterGroups == nil {
+			c.ClusterGroups = make(map[string][]string)
+		}
+		c.ClusterGroups[name] = members
+	}
+	err := c.SaveConfigFile()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *ConfigFile) SaveCtxClusterIdentity(ctxName string, identity domain.ClusterIdentity) error {
+	ctx, exists := c.Contexts[ctxName]
+	if !exists {
+		return fmt.Errorf("context %s does not exist", ctxName)
+	}
+	ctx.Server = identity.Server
+	ctx.CAFingerprint = identity.CAFingerprint
+	c.Contexts[ctxName] = ctx
+	err := c.SaveConfigFile()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *ConfigFile) SaveCtxStorageMode(ctxName string, mode string) error {
+	ctx := c.getOrCreateCtx(ctxName)
+	ctx.StorageMode = mode
+	c.Contexts[ctxName] = *ctx
+	err := c.SaveConfigFile()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetCtxStorageMode returns the storage mode in effect for the context: its own
+// mode when set, otherwise the global mode, otherwise local.
+func (c *ConfigFile) GetCtxStorageMode(ctxName string) (string, error) {
+	ctx, exists := c.Contexts[ctxName]
+	if exists && ctx.StorageMode != "" {
+		return ctx.StorageMode, nil
+	}
+	if c.StorageMode != "" {
+		return c.StorageMode, nil
+	}
+	return domain.LocalStorageMode.ToString(), nil
+}
+
 func (c *ConfigFile) RemoveCtx(ctxName string) error {
 	_, exists := c.Contexts[ctxName]
 	if exists {
@@ -46,7 +132,7 @@ func (c *ConfigFile) RemoveCtx(ctxName string) error {
 func (c *ConfigFile) ListContextsWithKeys() ([]string, error) {
 	var result []string
 	for ctxName, context := range c.Contexts {
-		if context.PrivateKey != "" {
+		if context.PrivateKey != "" || context.PublicKey != "" || context.SecretName != "" {
 			result = append(result, ctxName)
 		}
 	}
@@ -54,12 +140,13 @@ func (c *ConfigFile) ListContextsWithKeys() ([]string, error) {
 }
 
 func (c *ConfigFile) SaveConfigFile() error {
+	c.Version = currentConfigVersion
 	content, err := yaml.Marshal(c)
 	if err != nil {
 		return err
 	}
 
-	err = os.WriteFile(c.FilePath, content, 0644)
+	err = file.AtomicWriteFile(c.FilePath, content)
 	if err != nil {
 		return err
 	}
@@ -69,10 +156,53 @@ func (c *ConfigFile) SaveConfigFile() error {
 func (c *ConfigFile) SetPrivateKey(key string, ctxName string) error {
 	ctx := c.getOrCreateCtx(ctxName)
 	ctx.PrivateKey = key
+	ctx.PublicKey = ""
 	c.Contexts[ctxName] = *ctx
 	return nil
 }
 
+// SetPublicKey turns the context into an encrypt-only context, replacing any private
+// key or cluster reference it held before.
+func (c *ConfigFile) SetPublicKey(key string, ctxName string) error {
+	ctx := c.getOrCreateCtx(ctxName)
+	ctx.PublicKey = key
+	ctx.PrivateKey = ""
+	ctx.Namespace = ""
+	ctx.SecretName = ""
+	ctx.KeyName = ""
+	ctx.Source = domain.PublicKeyKeySource.ToString()
+	ctx.AddedAt = time.Now().UTC()
+	c.Contexts[ctxName] = *ctx
+	return nil
+}
+
+// SetClusterKey stores a private key together with the cluster secret it was read from.
+func (c *ConfigFile) SetClusterKey(key string, ctxName string, namespace string, secretName string, keyName string, addedAt time.Time) error {
+	ctx := c.getOrCreateCtx(ctxName)
+	ctx.PrivateKey = key
+	ctx.PublicKey = ""
+	ctx.Namespace = namespace
+	ctx.SecretName = secretName
+	ctx.KeyName = keyName
+	ctx.Source = domain.ClusterKeySource.ToString()
+	ctx.AddedAt = addedAt
+	c.Contexts[ctxName] = *ctx
+	return nil
+}
+
+// RefreshClusterKey replaces the private key of a context with the rotated key read
+// from its cluster secret, keeping the source reference and when it was added.
+func (c *ConfigFile) RefreshClusterKey(key string, ctxName string, refreshedAt time.Time) error {
+	ctx, exists := c.Contexts[ctxName]
+	if !exists {
+		return fmt.Errorf("context %s does not exist", ctxName)
+	}
+	ctx.PrivateKey = key
+	ctx.RefreshedAt = refreshedAt
+	c.Contexts[ctxName] = ctx
+	return nil
+}
+
 func (c *ConfigFile) getOrCreateCtx(ctxName string) *domain.CTX {
 	ctx, exists := c.Contexts[ctxName]
 	if !exists {
@@ -104,13 +234,53 @@ func (c *ConfigFile) SaveCtx(ctxName string, ctx *domain.CTX) error {
 	return nil
 }
 
-func newEmptyConfigFile(filePath string) *ConfigFile {
-	err := os.MkdirAll(filepath.Dir(filePath), 0777)
+func newEmptyConfigFile(filePath string) (*ConfigFile, error) {
+	err := os.MkdirAll(filepath.Dir(filePath), 0700)
 	if err != nil && !os.IsExist(err) {
-		return nil
+		return nil, fmt.Errorf("create config directory: %w", err)
 	}
 	return &ConfigFile{
+		Version:  currentConfigVersion,
 		FilePath: filePath,
 		Contexts: make(map[string]domain.CTX),
+	}, nil
+}
+
+// configMigrations maps a schema version to the step that upgrades a config from it
+// to the next version.
+var configMigrations = map[int]func(c *ConfigFile) error{
+	0: migrateConfigV0ToV1,
+}
+
+// migrate upgrades the config in memory to currentConfigVersion. The result is
+// persisted by the next SaveConfigFile.
+func (c *ConfigFile) migrate() error {
+	if c.Version > currentConfigVersion {
+		return fmt.Errorf("config file %s has version %d but this sopsctl only supports up to version %d, please upgrade sopsctl", c.FilePath, c.Version, currentConfigVersion)
 	}
+	for c.Version < currentConfigVersion {
+		step, ok := configMigrations[c.Version]
+		if !ok {
+			return fmt.Errorf("no migration for config version %d", c.Version)
+		}
+		if err := step(c); err != nil {
+			return fmt.Errorf("migrate config from version %d: %w", c.Version, err)
+		}
+		c.Version++
+	}
+	if c.Contexts == nil {
+		c.Contexts = make(map[string]domain.CTX)
+	}
+	return nil
+}
+
+// migrateConfigV0ToV1 drops the context entries unversioned releases left behind
+// when a key was looked up for a context that had none.
+func migrateConfigV0ToV1(c *ConfigFile) error {
+	for name, ctx := range c.Contexts {
+		if ctx == *domain.NewEmptyCtx() {
+			delete(c.Contexts, name)
+		}
+	}
+	return nil
 }
@@ -1,8 +1,12 @@
 package storage
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/file"
+	"time"
 
 	"gopkg.in/yaml.v3"
 	"k8s.io/client-go/util/homedir"
@@ -19,15 +23,34 @@ type LocalUserKeyStorageService struct {
 }
 
 func (l LocalUserKeyStorageService) SaveCtxReference(ctxName string, namespace string, secretName string, key string) error {
-	config := l.readConfigFromFileOrEmpty()
-	ctx := domain.NewReferenceCTX(namespace, secretName, key)
+	return l.updateConfig(func(config *ConfigFile) error {
+		ctx := domain.NewReferenceCTX(namespace, secretName, key)
+		ctx.Source = domain.ClusterKeySource.ToString()
+		ctx.AddedAt = time.Now().UTC()
+		if existing, err := config.GetCtx(ctxName); err == nil {
+			ctx.StorageMode = existing.StorageMode
+		}
+		return config.SaveCtx(ctxName, ctx)
+	})
+}
+
+func (l LocalUserKeyStorageService) SaveCtx(ctxName string, ctx domain.CTX) error {
+	return l.updateConfig(func(config *ConfigFile) error {
+		return config.SaveCtx(ctxName, &ctx)
+	})
+}
 
-	err := config.SaveCtx(ctxName, ctx)
-	return err
+func (l LocalUserKeyStorageService) SetCtxClusterIdentity(ctxName string, identity domain.ClusterIdentity) error {
+	return l.updateConfig(func(config *ConfigFile) error {
+		return config.SaveCtxClusterIdentity(ctxName, identity)
+	})
 }
 
 func (l LocalUserKeyStorageService) GetCtx(ctxName string) (*domain.CTX, error) {
-	config := l.readConfigFromFileOrEmpty()
+	config, err := l.readConfig()
+	if err != nil {
+		return nil, err
+	}
 	ctx, err := config.GetCtx(ctxName)
 	if err != nil {
 		return nil, err
@@ -36,7 +59,10 @@ func (l LocalUserKeyStorageService) GetCtx(ctxName string) (*domain.CTX, error)
 }
 
 func (l LocalUserKeyStorageService) GetStorageMode() (domain.StorageMode, error) {
-	config := l.readConfigFromFileOrEmpty()
+	config, err := l.readConfig()
+	if err != nil {
+		return "", err
+	}
 	mode, err := config.GetStorageMode()
 	if err != nil {
 		return "", err
@@ -45,69 +71,200 @@ func (l LocalUserKeyStorageService) GetStorageMode() (domain.StorageMode, error)
 }
 
 func (l LocalUserKeyStorageService) SetStorageMode(mode domain.StorageMode) error {
-	config := l.readConfigFromFileOrEmpty()
-	err := config.SaveStorageMode(mode.ToString())
+	return l.updateConfig(func(config *ConfigFile) error {
+		return config.SaveStorageMode(mode.ToString())
+	})
+}
+
+func (l LocalUserKeyStorageService) SetCtxStorageMode(ctxName string, mode domain.StorageMode) error {
+	return l.updateConfig(func(config *ConfigFile) error {
+		return config.SaveCtxStorageMode(ctxName, mode.ToString())
+	})
+}
+
+func (l LocalUserKeyStorageService) GetCtxStorageMode(ctxName string) (domain.StorageMode, error) {
+	config, err := l.readConfig()
 	if err != nil {
-		return err
+		return "", err
+	}
+	mode, err := config.GetCtxStorageMode(ctxName)
+	if err != nil {
+		return "", err
+	}
+	return domain.StorageMode(mode), nil
+}
+
+func (l LocalUserKeyStorageService) SetAuditHashChain(enabled bool) error {
+	return l.updateConfig(func(config *ConfigFile) error {
+		return config.SaveAuditHashChain(enabled)
+	})
+}
+
+func (l LocalUserKeyStorageService) GetAuditHashChain() (bool, error) {
+	config, err := l.readConfig()
+	if err != nil {
+		return false, err
 	}
-	return nil
+	return config.AuditHashChain, nil
 }
 
-func (l LocalUserKeyStorageService) RemoveKeyForContext(ctx string) error {
-	config := l.readConfigFromFileOrEmpty()
-	err := config.RemoveCtx(ctx)
+func (l LocalUserKeyStorageService) SetSopsKeyFallback(enabled bool) error {
+	return l.updateConfig(func(config *ConfigFile) error {
+		return config.SaveSopsKeyFallback(enabled)
+	})
+}
+
+func (l LocalUserKeyStorageService) GetSopsKeyFallback() (bool, error) {
+	config, err := l.readConfig()
 	if err != nil {
-		return err
+		return false, err
 	}
-	return nil
+	return config.SopsKeyFallback, nil
+}
+
+func (l LocalUserKeyStorageService) SetClusterGroup(name string, members []string) error {
+	return l.updateConfig(func(config *ConfigFile) error {
+		return config.SaveClusterGroup(name, members)
+	})
+}
+
+func (l LocalUserKeyStorageService) GetClusterGroups() (map[string][]string, error) {
+	config, err := l.readConfig()
+	if err != nil {
+		return nil, err
+	}
+	return config.ClusterGroups, nil
+}
+
+func (l LocalUserKeyStorageService) RemoveKeyForContext(ctx string) error {
+	return l.updateConfig(func(config *ConfigFile) error {
+		return config.RemoveCtx(ctx)
+	})
 }
 
 func (l LocalUserKeyStorageService) ListContextsWithKeys() ([]string, error) {
-	config := l.readConfigFromFileOrEmpty()
+	config, err := l.readConfig()
+	if err != nil {
+		return nil, err
+	}
 	return config.ListContextsWithKeys()
 }
 
 func (l LocalUserKeyStorageService) SavePrivateKey(key string, ctxName string) error {
-	config := l.readConfigFromFileOrEmpty()
-	err := config.SetPrivateKey(key, ctxName)
+	return l.updateConfig(func(config *ConfigFile) error {
+		err := config.SetPrivateKey(key, ctxName)
+		if err != nil {
+			return err
+		}
+		return config.SaveConfigFile()
+	})
+}
+
+func (l LocalUserKeyStorageService) SavePublicKey(key string, ctxName string) error {
+	return l.updateConfig(func(config *ConfigFile) error {
+		err := config.SetPublicKey(key, ctxName)
+		if err != nil {
+			return err
+		}
+		return config.SaveConfigFile()
+	})
+}
+
+func (l LocalUserKeyStorageService) SaveClusterKey(key string, ctxName string, namespace string, secretName string, keyName string) error {
+	return l.updateConfig(func(config *ConfigFile) error {
+		err := config.SetClusterKey(key, ctxName, namespace, secretName, keyName, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		return config.SaveConfigFile()
+	})
+}
+
+func (l LocalUserKeyStorageService) RefreshClusterKey(key string, ctxName string) error {
+	return l.updateConfig(func(config *ConfigFile) error {
+		err := config.RefreshClusterKey(key, ctxName, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		return config.SaveConfigFile()
+	})
+}
+
+func (l LocalUserKeyStorageService) GetPrivateKey(ctxName string) (string, error) {
+	config, err := l.readConfig()
+	if err != nil {
+		return "", err
+	}
+	return config.GetPrivateKey(ctxName)
+}
+
+// updateConfig reads the config file and passes it to update while holding the
+// config lock, so saves made by update cannot race with another sopsctl process.
+func (l LocalUserKeyStorageService) updateConfig(update func(config *ConfigFile) error) error {
+	path, err := l.getAbsoluteFilePath()
 	if err != nil {
 		return err
 	}
-	err = config.SaveConfigFile()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	lock, err := file.AcquireLock(path + ".lock")
 	if err != nil {
 		return err
 	}
-	return nil
-}
+	defer func() {
+		_ = lock.Release()
+	}()
 
-func (l LocalUserKeyStorageService) GetPrivateKey(ctxName string) (string, error) {
-	config := l.readConfigFromFileOrEmpty()
-	return config.GetPrivateKey(ctxName)
+	config, err := l.readConfig()
+	if err != nil {
+		return err
+	}
+	return update(config)
 }
 
-func (l LocalUserKeyStorageService) readConfigFromFileOrEmpty() *ConfigFile {
+// readConfig loads the config file, or an empty config when none exists yet. A file
+// that cannot be read or parsed is reported as an error and copied to a .bak file,
+// it is never replaced by an empty config.
+func (l LocalUserKeyStorageService) readConfig() (*ConfigFile, error) {
 	file, err := l.getAbsoluteFilePath()
 	if err != nil {
-		return newEmptyConfigFile(file)
+		return nil, err
 	}
 
 	data, err := os.ReadFile(file)
-	if err != nil {
+	if os.IsNotExist(err) {
 		return newEmptyConfigFile(file)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("read config file %s: %w", file, err)
+	}
 
-	var config = newEmptyConfigFile(file)
-	err = yaml.Unmarshal(data, &config)
+	config, err := newEmptyConfigFile(file)
 	if err != nil {
-		return newEmptyConfigFile(file)
+		return nil, err
+	}
+	// files written before versioning have no version field and decode as version 0
+	config.Version = 0
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		backupFile := file + ".bak"
+		if backupErr := os.WriteFile(backupFile, data, 0600); backupErr != nil {
+			return nil, fmt.Errorf("config file %s is corrupt (%v) and could not be backed up: %w", file, err, backupErr)
+		}
+		return nil, fmt.Errorf("config file %s is corrupt, a copy was saved to %s. Fix or remove the file to continue: %w", file, backupFile, err)
+	}
+	config.FilePath = file
+	if err := config.migrate(); err != nil {
+		return nil, err
 	}
-	return config
+	return config, nil
 }
 
 func (l LocalUserKeyStorageService) getAbsoluteFilePath() (string, error) {
 	hd := homedir.HomeDir()
 	if hd == "" {
-		return "", nil
+		return "", fmt.Errorf("could not determine home directory for the sopsctl config")
 	}
 	absFilePath := hd + "/.sopsctl/" + l.fileName
 	return absFilePath, nil
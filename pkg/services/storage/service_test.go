@@ -1,6 +1,13 @@
 package storage
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sopsctl/pkg/domain"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -67,3 +74,263 @@ func TestLocalUserKeyStorageService_SaveMultiplePrivateKey_GetPrivateKey(t *test
 		t.Fatalf("expected key %s, got %s", secondKey, secondPrivateKey)
 	}
 }
+
+func TestLocalUserKeyStorageService_GetCtxStorageMode_FallsBackToGlobal(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	uut := NewLocalUserKeyStorageService()
+
+	mode, err := uut.GetCtxStorageMode("dev")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if mode != domain.LocalStorageMode {
+		t.Fatalf("expected default mode %s, got %s", domain.LocalStorageMode, mode)
+	}
+
+	if err := uut.SetStorageMode(domain.InClusterStorageMode); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := uut.SetCtxStorageMode("dev", domain.LocalStorageMode); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	devMode, _ := uut.GetCtxStorageMode("dev")
+	if devMode != domain.LocalStorageMode {
+		t.Fatalf("expected mode %s for dev, got %s", domain.LocalStorageMode, devMode)
+	}
+	prodMode, _ := uut.GetCtxStorageMode("prod")
+	if prodMode != domain.InClusterStorageMode {
+		t.Fatalf("expected mode %s for prod, got %s", domain.InClusterStorageMode, prodMode)
+	}
+}
+
+func TestLocalUserKeyStorageService_SaveCtxReference_KeepsCtxStorageMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	uut := NewLocalUserKeyStorageService()
+
+	if err := uut.SetCtxStorageMode("prod", domain.InClusterStorageMode); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := uut.SaveCtxReference("prod", "flux-system", "sops-age", "age.agekey"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	ctx, err := uut.GetCtx("prod")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if ctx.StorageMode != domain.InClusterStorageMode.ToString() {
+		t.Fatalf("expected storage mode %s, got %s", domain.InClusterStorageMode, ctx.StorageMode)
+	}
+	if ctx.SecretName != "sops-age" {
+		t.Fatalf("expected secret name sops-age, got %s", ctx.SecretName)
+	}
+}
+
+func TestLocalUserKeyStorageService_CorruptConfig_IsNotOverwritten(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, ".sopsctl", "sopsctl-config.yaml")
+	corrupt := []byte("contexts: [this is: not valid")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, corrupt, 0600); err != nil {
+		t.Fatal(err)
+	}
+	uut := NewLocalUserKeyStorageService()
+
+	err := uut.SavePrivateKey("some-key", "some-CTX")
+
+	if err == nil {
+		t.Fatal("expected error for corrupt config file")
+	}
+	content, _ := os.ReadFile(configPath)
+	if string(content) != string(corrupt) {
+		t.Fatalf("expected corrupt config to be left untouched, got: %s", content)
+	}
+	backup, err := os.ReadFile(configPath + ".bak")
+	if err != nil {
+		t.Fatalf("expected backup file, got: %v", err)
+	}
+	if string(backup) != string(corrupt) {
+		t.Fatalf("expected backup to hold the corrupt config, got: %s", backup)
+	}
+}
+
+func TestLocalUserKeyStorageService_SaveConfig_WritesVersionAndPermissions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	uut := NewLocalUserKeyStorageService()
+
+	if err := uut.SavePrivateKey("some-key", "some-CTX"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	configPath := filepath.Join(home, ".sopsctl", "sopsctl-config.yaml")
+	info, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Fatalf("expected permissions 0600, got %o", info.Mode().Perm())
+	}
+	content, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(content), fmt.Sprintf("version: %d", currentConfigVersion)) {
+		t.Fatalf("expected config to carry schema version, got: %s", content)
+	}
+}
+
+func TestLocalUserKeyStorageService_MigratesUnversionedConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, ".sopsctl", "sopsctl-config.yaml")
+	unversioned := []byte(`storagemode: local
+filepath: /old/path/sopsctl-config.yaml
+contexts:
+    dev:
+        privatekey: dev-key
+        namespace: ""
+        secretname: ""
+        keyname: ""
+    stale:
+        privatekey: ""
+        namespace: ""
+        secretname: ""
+        keyname: ""
+`)
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, unversioned, 0644); err != nil {
+		t.Fatal(err)
+	}
+	uut := NewLocalUserKeyStorageService()
+
+	config, err := uut.readConfig()
+
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if config.Version != currentConfigVersion {
+		t.Fatalf("expected version %d, got %d", currentConfigVersion, config.Version)
+	}
+	if config.FilePath != configPath {
+		t.Fatalf("expected file path %s, got %s", configPath, config.FilePath)
+	}
+	if _, exists := config.Contexts["stale"]; exists {
+		t.Fatal("expected empty context to be dropped by migration")
+	}
+	if config.Contexts["dev"].PrivateKey != "dev-key" {
+		t.Fatalf("expected dev key to survive migration, got %q", config.Contexts["dev"].PrivateKey)
+	}
+}
+
+func TestLocalUserKeyStorageService_NewerConfigVersion_IsRefused(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, ".sopsctl", "sopsctl-config.yaml")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte(fmt.Sprintf("version: %d\n", currentConfigVersion+1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	uut := NewLocalUserKeyStorageService()
+
+	_, err := uut.ListContextsWithKeys()
+
+	if err == nil {
+		t.Fatal("expected error for config written by a newer version")
+	}
+}
+
+func TestLocalUserKeyStorageService_ConcurrentSaves_KeepAllKeys(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	const writers = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			uut := NewLocalUserKeyStorageService()
+			errs <- uut.SavePrivateKey(fmt.Sprintf("key-%d", i), fmt.Sprintf("ctx-%d", i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+
+	contexts, err := NewLocalUserKeyStorageService().ListContextsWithKeys()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(contexts) != writers {
+		t.Fatalf("expected %d contexts, got %d", writers, len(contexts))
+	}
+}
+
+func TestLocalUserKeyStorageService_SaveClusterKey_RecordsProvenance(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	uut := NewLocalUserKeyStorageService()
+
+	if err := uut.SaveClusterKey("some-key", "prod", "flux-system", "sops-age", "age.agekey"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	ctx, err := NewLocalUserKeyStorageService().GetCtx("prod")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if ctx.PrivateKey != "some-key" || ctx.Namespace != "flux-system" || ctx.SecretName != "sops-age" || ctx.KeyName != "age.agekey" {
+		t.Fatalf("expected key and source reference to be stored, got %+v", ctx)
+	}
+	if ctx.Source != domain.ClusterKeySource.ToString() {
+		t.Fatalf("expected source %s, got %s", domain.ClusterKeySource, ctx.Source)
+	}
+	if ctx.AddedAt.IsZero() {
+		t.Fatal("expected added-at timestamp to be recorded")
+	}
+}
+
+func TestLocalUserKeyStorageService_RefreshClusterKey_KeepsProvenance(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	uut := NewLocalUserKeyStorageService()
+	if err := uut.SaveClusterKey("old-key", "prod", "flux-system", "sops-age", "age.agekey"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	before, err := uut.GetCtx("prod")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if err := uut.RefreshClusterKey("new-key", "prod"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	after, err := NewLocalUserKeyStorageService().GetCtx("prod")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if after.PrivateKey != "new-key" {
+		t.Fatalf("expected the refreshed key to be stored, got %s", after.PrivateKey)
+	}
+	if !after.AddedAt.Equal(before.AddedAt) {
+		t.Fatalf("expected added-at %v to be kept, got %v", before.AddedAt, after.AddedAt)
+	}
+	if after.RefreshedAt.IsZero() {
+		t.Fatal("expected refreshed-at timestamp to be recorded")
+	}
+	if after.Namespace != "flux-system" || after.SecretName != "sops-age" || after.KeyName != "age.agekey" || after.Source != before.Source {
+		t.Fatalf("expected source reference to be kept, got %+v", after)
+	}
+	if err := uut.RefreshClusterKey("new-key", "missing"); err == nil {
+		t.Fatal("expected an error for a missing context")
+	}
+}
@@ -0,0 +1,32 @@
+// Package testutil holds the test doubles and setup shared by the command tests.
+package testutil
+
+import (
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/key"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/require"
+)
+
+// NopAuditLog is an audit log that records nothing.
+type NopAuditLog struct{}
+
+func (NopAuditLog) Record(domain.AuditEntry)                                {}
+func (NopAuditLog) Entries(domain.AuditFilter) ([]domain.AuditEntry, error) { return nil, nil }
+func (NopAuditLog) Verify() error                                           { return nil }
+
+// NewKeyManager returns a key manager whose config lives in a temporary HOME, with an
+// encrypt-only context holding a fresh public key for each of ctxNames.
+func NewKeyManager(t *testing.T, ctxNames ...string) *key.GlobalSopsKeyManager {
+	t.Setenv("HOME", t.TempDir())
+	skm := key.NewGlobalSopsKeyManager()
+	for _, ctxName := range ctxNames {
+		identity, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		_, err = skm.AddPublicKey(ctxName, identity.Recipient().String())
+		require.NoError(t, err)
+	}
+	return skm
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sopsctl/pkg/domain"
 	"sopsctl/pkg/services/helpers"
 
 	"github.com/spf13/cobra"
@@ -35,6 +37,57 @@ func UseGlobalFlags(cmd *cobra.Command) (*GlobalFlags, error) {
 	}, nil
 }
 
+// UseDecryptCluster returns the context to decrypt the file with. An explicit --cluster is
+// used as given, for a cluster list or group the first member with a usable key the file
+// is encrypted for is used. Otherwise the stored key matching one of the file's age recipients is selected,
+// preferring the current context.
+func UseDecryptCluster(cmd *cobra.Command, filePath string, skm domain.SopsKeyManager, es domain.EncryptionService) (string, error) {
+	if cmd.Flags().Changed("cluster") {
+		cluster := cmd.Flags().Lookup("cluster").Value.String()
+		clusters, err := skm.ResolveClusters(cluster)
+		if err != nil {
+			return "", err
+		}
+		if len(clusters) == 1 {
+			return clusters[0], nil
+		}
+		recipients, err := es.Recipients(filePath)
+		if err != nil {
+			return "", err
+		}
+		for _, member := range clusters {
+			// members added to a group after the file was encrypted cannot open it
+			if len(recipients) > 0 {
+				publicKey, err := skm.GetPublicKey(member)
+				if err != nil || !slices.Contains(recipients, publicKey) {
+					continue
+				}
+			}
+			if _, err := skm.GetPrivateKey(member); err == nil {
+				return member, nil
+			}
+		}
+		return "", fmt.Errorf("none of the contexts in %s has a SOPS key the file is encrypted for", cluster)
+	}
+	recipients, err := es.Recipients(filePath)
+	if err != nil {
+		return "", err
+	}
+	if len(recipients) == 0 {
+		gFlags, err := UseGlobalFlags(cmd)
+		if err != nil {
+			return "", err
+		}
+		return gFlags.Cluster, nil
+	}
+	current, _ := helpers.GetCtxNameFromCurrent()
+	cluster, err := skm.FindCtxForRecipients(recipients, current)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", filePath, err)
+	}
+	return cluster, nil
+}
+
 func UserFileArg(args []string) (string, error) {
 	if len(args) == 0 {
 		return "", fmt.Errorf("no file specified")
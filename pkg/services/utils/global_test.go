@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sopsctl/pkg/domain"
+	"sopsctl/pkg/services/encryption"
+	"sopsctl/pkg/services/testutil"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseDecryptCluster_PicksGroupMemberTheFileIsEncryptedFor(t *testing.T) {
+	skm := testutil.NewKeyManager(t)
+	identities := map[string]*age.X25519Identity{}
+	for _, ctx := range []string{"prod-eu", "prod-us"} {
+		identity, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		require.NoError(t, skm.ImportCtx(ctx, domain.CTX{PrivateKey: identity.String(), Source: "private-key"}))
+		identities[ctx] = identity
+	}
+	es := encryption.NewSopsAgeDecryptStrategy()
+	path := filepath.Join(t.TempDir(), "db.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("apiVersion: v1\nkind: Secret\ndata:\n  password: aHVudGVyMg==\n"), 0600))
+	// prod-eu joined after the file was encrypted for prod-us only
+	encrypted, err := es.EncryptFile(path, domain.DefaultEncryptedRegex, identities["prod-us"].Recipient().String())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, encrypted, 0600))
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("cluster", "", "")
+	require.NoError(t, cmd.Flags().Set("cluster", "prod-eu,prod-us"))
+
+	cluster, err := UseDecryptCluster(cmd, path, skm, es)
+	require.NoError(t, err)
+	assert.Equal(t, "prod-us", cluster)
+}
@@ -0,0 +1,41 @@
+// Package yamlnode edits YAML mappings in place, keeping the comments and key order of
+// the document.
+package yamlnode
+
+import "gopkg.in/yaml.v3"
+
+// MappingValue returns the value node of key in a YAML mapping, or nil when the key is missing.
+func MappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// SetMappingValue sets key to a string value, adding it at the end when it is missing.
+func SetMappingValue(mapping *yaml.Node, key string, value string) {
+	SetMappingNode(mapping, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+}
+
+// SetMappingNode sets key to the value node, adding it at the end when it is missing.
+func SetMappingNode(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// RemoveMappingKey removes key and its value from the mapping.
+func RemoveMappingKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}